@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal"
+	"github.com/chuckha/kubeyaml.com/backend/internal/adapters/admission"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
+)
+
+func main() {
+	addr := flag.String("addr", ":8443", "the address to serve admission reviews on")
+	certFile := flag.String("tls-cert-file", "", "the file with the x509 certificate to serve with")
+	keyFile := flag.String("tls-private-key-file", "", "the file with the private key of the certificate")
+	version := flag.String("kubernetes-version", "", "the kubernetes version of the cluster, such as 1.12 (required). "+
+		"A version newer than every available schema is validated against the newest one and unknown keys are only warned about")
+	crds := flag.String("crds", "", "comma separated list of files or directories with custom resource definitions to validate against")
+	flag.Parse()
+	if *version == "" {
+		// the newest schema kubeyaml has is often older than the cluster and would deny fields it doesn't know yet
+		fmt.Println("-kubernetes-version is required")
+		os.Exit(1)
+	}
+
+	opts := make([]validation.ServiceOption, 0)
+	if *crds != "" {
+		registry := crd.NewRegistry()
+		for _, path := range strings.Split(*crds, ",") {
+			if err := registry.LoadPath(path); err != nil {
+				fmt.Printf("failed to load custom resource definitions: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		custom, err := validation.NewCustomResources(registry)
+		if err != nil {
+			fmt.Printf("failed to load custom resource definitions: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, validation.WithCustomResources(custom))
+	}
+
+	svc, err := validation.NewService(opts...)
+	if err != nil {
+		fmt.Printf("failed to create the validation service: %v\n", err)
+		os.Exit(1)
+	}
+	serverOpts := []admission.ServerOption{
+		admission.WithAddr(*addr),
+		admission.WithTLS(*certFile, *keyFile),
+	}
+	// fail now rather than on every request
+	available := svc.Versions()
+	switch {
+	case contains(available, *version):
+		serverOpts = append(serverOpts, admission.WithVersion(*version))
+	case len(available) > 0 && newer(*version, available[0]):
+		fmt.Printf("no schemas for kubernetes %s, validating against %s and only warning about unknown keys\n", *version, available[0])
+		serverOpts = append(serverOpts, admission.WithVersion(available[0]), admission.WithNewerCluster())
+	default:
+		fmt.Printf("no schemas for kubernetes %s, available versions are %s\n", *version, strings.Join(available, ", "))
+		os.Exit(1)
+	}
+	svr := admission.NewServer(svc, serverOpts...)
+	if err := svr.Run(); err != nil {
+		fmt.Printf("failed to serve admission reviews: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newer is true when version a is newer than version b, versions that can't be compared aren't.
+func newer(a, b string) bool {
+	sorted, err := internal.SortVersions(a, b)
+	return err == nil && a != b && sorted[0] == a
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
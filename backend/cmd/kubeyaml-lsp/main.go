@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal"
+	"github.com/chuckha/kubeyaml.com/backend/internal/adapters/lsp"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/data"
+)
+
+// kubeyaml-lsp speaks the Language Server Protocol on stdin and stdout, everything else goes to stderr.
+func main() {
+	versionList := flag.String("versions", "", "comma separated list of kubernetes versions to validate against, hover and completion use the newest (default the newest available version)")
+	crds := flag.String("crds", "", "comma separated list of files or directories with custom resource definitions to validate against")
+	flag.Parse()
+
+	available, err := data.Versions()
+	if err != nil {
+		fail("failed to list the available versions: %v", err)
+	}
+	if *versionList == "" {
+		if len(available) == 0 {
+			fail("no kubernetes versions to validate against")
+		}
+		*versionList = available[0]
+	}
+	versions, err := internal.SortVersions(strings.Split(*versionList, ",")...)
+	if err != nil {
+		fail("failed to sort versions: %v", err)
+	}
+
+	var custom *kubernetes.CustomResources
+	if *crds != "" {
+		registry := crd.NewRegistry()
+		for _, path := range strings.Split(*crds, ",") {
+			if err := registry.LoadPath(path); err != nil {
+				fail("failed to load custom resource definitions: %v", err)
+			}
+		}
+		custom, err = kubernetes.NewCustomResources(registry)
+		if err != nil {
+			fail("failed to load custom resource definitions: %v", err)
+		}
+	}
+	finder := kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io")
+	if custom != nil {
+		finder.AddCustomResources(custom)
+	}
+	validators := make([]*kubernetes.Validator, len(versions))
+	for i, version := range versions {
+		resolver, err := kubernetes.NewResolver(version)
+		if err != nil {
+			fail("failed to get a resolver for version %q: %v", version, err)
+		}
+		if custom != nil {
+			resolver.AddCustomResources(custom)
+		}
+		validators[i] = kubernetes.NewValidator(resolver)
+	}
+	deprecations, err := kubernetes.NewDeprecationIndex(available...)
+	if err != nil {
+		fail("failed to compare the available versions: %v", err)
+	}
+
+	svr := lsp.NewServer(finder, validators, lsp.WithDeprecations(deprecations))
+	if err := svr.Run(os.Stdin, os.Stdout); err != nil {
+		fail("%v", err)
+	}
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
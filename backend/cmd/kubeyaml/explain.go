@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/data"
+)
+
+// explain prints the documentation of a field, such as `kubeyaml explain apps/v1 Deployment spec.strategy`.
+func explain(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("kubeyaml explain", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: kubeyaml explain [flags] apiVersion kind [path]\n\n")
+		fmt.Fprintf(stderr, "Describes a field of a kubernetes object and the fields under it, such as spec.strategy of an apps/v1 Deployment.\n")
+		fmt.Fprintf(stderr, "Items of arrays don't need an index, spec.template.spec.containers.image works.\n\n")
+		fs.PrintDefaults()
+	}
+	version := fs.String("version", "", "the kubernetes version to look the field up in (default the newest available version)")
+	crds := fs.String("crds", "", "comma separated list of files or directories with custom resource definitions to look custom resources up in")
+	format := fs.String("format", "text", "how to print the field: text or json")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() < 2 || fs.NArg() > 3 {
+		fs.Usage()
+		return exitError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q (should be text or json)\n", *format)
+		return exitError
+	}
+	apiVersion, kind := fs.Arg(0), fs.Arg(1)
+	var path []string
+	if p := strings.Trim(fs.Arg(2), "."); p != "" {
+		path = strings.Split(p, ".")
+	}
+
+	if *version == "" {
+		available, err := data.Versions()
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to list the available versions: %v\n", err)
+			return exitError
+		}
+		if len(available) == 0 {
+			fmt.Fprintf(stderr, "no kubernetes versions to look fields up in\n")
+			return exitError
+		}
+		*version = available[0]
+	}
+	resolver, err := kubernetes.NewResolver(*version)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to get a resolver for version %q: %v\n", *version, err)
+		return exitError
+	}
+	finder := kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io")
+	if *crds != "" {
+		custom, err := loadCustomResources(strings.Split(*crds, ","))
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to load custom resource definitions: %v\n", err)
+			return exitError
+		}
+		resolver.AddCustomResources(custom)
+		finder.AddCustomResources(custom)
+	}
+
+	explanation, err := kubernetes.NewValidator(resolver).Explain(finder.APIKey(apiVersion, kind), path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return exitInvalid
+	}
+	if *format == "json" {
+		out, err := json.MarshalIndent(explanation, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to marshal the explanation: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintf(stdout, "%s\n", out)
+		return exitOK
+	}
+	printExplanation(stdout, apiVersion, kind, explanation)
+	return exitOK
+}
+
+// printExplanation looks like `kubectl explain` so it's familiar.
+func printExplanation(w io.Writer, apiVersion, kind string, e *kubernetes.Explanation) {
+	fmt.Fprintf(w, "KIND:       %s\n", kind)
+	fmt.Fprintf(w, "VERSION:    %s\n", apiVersion)
+	fmt.Fprintf(w, "KUBERNETES: %s\n\n", e.Version)
+	if e.Path != "" {
+		fmt.Fprintf(w, "FIELD:      %s <%s>%s\n\n", e.Path[strings.LastIndex(e.Path, ".")+1:], e.Type, required(e.Required))
+	}
+	fmt.Fprintf(w, "DESCRIPTION:\n")
+	printIndented(w, e.Description, "     ")
+	if len(e.Fields) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nFIELDS:\n")
+	for _, f := range e.Fields {
+		fmt.Fprintf(w, "   %s\t<%s>%s\n", f.Name, f.Type, required(f.Required))
+		printIndented(w, f.Description, "     ")
+		fmt.Fprintln(w)
+	}
+}
+
+func printIndented(w io.Writer, text, indent string) {
+	if text == "" {
+		text = "<empty>"
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(w, "%s%s\n", indent, line)
+	}
+}
+
+func required(r bool) string {
+	if r {
+		return " -required-"
+	}
+	return ""
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/data"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/deprecation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/report"
+)
+
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+
+	stdinName = "-"
+)
+
+type CLIArgs struct {
+	Versions string
+	CRDs     string
+	Severity string
+	Format   string
+	Quiet    bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "explain" {
+		return explain(args[1:], stdout, stderr)
+	}
+	fs := flag.NewFlagSet("kubeyaml", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: kubeyaml [flags] [file or directory ...]\n")
+		fmt.Fprintf(stderr, "       kubeyaml explain [flags] apiVersion kind [path]\n\n")
+		fmt.Fprintf(stderr, "Validates kubernetes YAML files. Directories are searched for .yaml and .yml files.\n")
+		fmt.Fprintf(stderr, "Reads from stdin when no paths are given or a path is %q.\n\n", stdinName)
+		fs.PrintDefaults()
+	}
+	ca := &CLIArgs{}
+	fs.StringVar(&ca.Versions, "versions", "", "comma separated list of kubernetes versions to validate against (default the newest available version)")
+	fs.StringVar(&ca.CRDs, "crds", "", "comma separated list of files or directories with custom resource definitions to validate against")
+	fs.StringVar(&ca.Severity, "severity", string(problem.SeverityWarning), "the least severe problem to print: error, warning or info")
+	fs.StringVar(&ca.Format, "format", report.Formats[0], "how to print what is found: "+strings.Join(report.Formats, ", "))
+	fs.BoolVar(&ca.Quiet, "quiet", false, "leave out the files that have nothing to report")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	severity, err := problem.ParseSeverity(ca.Severity)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return exitError
+	}
+	reporter, err := report.New(ca.Format)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return exitError
+	}
+
+	available, err := data.Versions()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to list the available versions: %v\n", err)
+		return exitError
+	}
+	if ca.Versions == "" {
+		if len(available) == 0 {
+			fmt.Fprintf(stderr, "no kubernetes versions to validate against\n")
+			return exitError
+		}
+		ca.Versions = available[0]
+	}
+	versions, err := internal.SortVersions(strings.Split(ca.Versions, ",")...)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to sort versions: %v\n", err)
+		return exitError
+	}
+	var custom *kubernetes.CustomResources
+	if ca.CRDs != "" {
+		custom, err = loadCustomResources(strings.Split(ca.CRDs, ","))
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to load custom resource definitions: %v\n", err)
+			return exitError
+		}
+	}
+	finder := kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io")
+	validators := make([]kubernetes.VersionValidator, len(versions))
+	for i, version := range versions {
+		resolver, err := kubernetes.NewResolver(version)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to get a resolver for version %q: %v\n", version, err)
+			return exitError
+		}
+		if custom != nil {
+			resolver.AddCustomResources(custom)
+		}
+		validators[i] = kubernetes.NewValidator(resolver)
+	}
+	if custom != nil {
+		finder.AddCustomResources(custom)
+	}
+	// every available version is compared to find deprecated and removed apiVersions, not just the ones validated against
+	deprecations, err := kubernetes.NewDeprecationIndex(available...)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to compare the available versions: %v\n", err)
+		return exitError
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{stdinName}
+	}
+	files, err := findFiles(paths)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return exitError
+	}
+
+	c := &checker{
+		loader:       kubernetes.NewLoader(),
+		finder:       finder,
+		validators:   validators,
+		deprecations: deprecations,
+		severity:     severity,
+	}
+	code := exitOK
+	rep := &report.Report{Files: make([]string, 0, len(files))}
+	for _, file := range files {
+		found, invalid, err := c.checkFile(file, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", displayName(file), err)
+			code = exitError
+			continue
+		}
+		// only errors fail, warnings and info are advice
+		if invalid && code == exitOK {
+			code = exitInvalid
+		}
+		// files with nothing to report are left out when quiet
+		if len(found) > 0 || !ca.Quiet {
+			rep.Files = append(rep.Files, displayName(file))
+		}
+		rep.Findings = append(rep.Findings, found...)
+	}
+	report.Sort(rep.Findings)
+	if err := reporter.Write(stdout, rep); err != nil {
+		fmt.Fprintf(stderr, "failed to write the report: %v\n", err)
+		return exitError
+	}
+	return code
+}
+
+func loadCustomResources(paths []string) (*kubernetes.CustomResources, error) {
+	registry := crd.NewRegistry()
+	for _, path := range paths {
+		if err := registry.LoadPath(path); err != nil {
+			return nil, err
+		}
+	}
+	return kubernetes.NewCustomResources(registry)
+}
+
+// findFiles expands directories into the YAML files found inside of them.
+func findFiles(paths []string) ([]string, error) {
+	files := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p == stdinName {
+			files = append(files, p)
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			switch filepath.Ext(path) {
+			case ".yaml", ".yml":
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func displayName(file string) string {
+	if file == stdinName {
+		return "<stdin>"
+	}
+	return file
+}
+
+type checker struct {
+	loader       *kubernetes.Loader
+	finder       *kubernetes.APIKeyer
+	validators   []kubernetes.VersionValidator
+	deprecations *deprecation.Index
+	severity     problem.Severity
+}
+
+// checkFile validates every document in a file against every version.
+// It returns what it finds at the checker's severity or worse and whether anything it found, reported or not, is an error.
+func (c *checker) checkFile(file string, stdin io.Reader) ([]*report.Finding, bool, error) {
+	var reader io.Reader = stdin
+	if file != stdinName {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, false, err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	found := make([]*report.Finding, 0)
+	add := func(document int, version string, errs ...error) {
+		for _, err := range errs {
+			found = append(found, &report.Finding{File: displayName(file), Document: document, Version: version, Detail: kubernetes.DetailOf(err)})
+		}
+	}
+	inputs, err := c.loader.Load(reader)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, input := range inputs {
+		problems := kubernetes.Check(input, c.finder, c.deprecations, c.validators...)
+		// problems with the document itself are the same in every version
+		add(input.Index, "", problems.Document...)
+		for _, v := range c.validators {
+			add(input.Index, v.Version(), problems.Versions[v.Version()]...)
+		}
+	}
+
+	invalid := false
+	reported := found[:0]
+	for _, f := range found {
+		if f.Severity.AtLeast(problem.SeverityError) {
+			invalid = true
+		}
+		if f.Severity.AtLeast(c.severity) {
+			reported = append(reported, f)
+		}
+	}
+	return reported, invalid, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+`
+	invalidPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx:1.15
+    imagePullPolicy: 3
+`
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(configMap), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "pod.yml"), []byte(invalidPod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not yaml: ["), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name  string
+		args  []string
+		stdin string
+		code  int
+		// stdout and stderr are expected to contain every string
+		stdout []string
+		stderr []string
+		// absent must not be in stdout
+		absent []string
+	}{
+		{
+			name:   "valid stdin",
+			stdin:  configMap,
+			code:   exitOK,
+			stdout: []string{"<stdin>: ok"},
+		},
+		{
+			name:   "invalid stdin",
+			stdin:  invalidPod,
+			code:   exitInvalid,
+			stdout: []string{"<stdin>:9:22: error KY003: 1.12: [spec.containers.0.imagePullPolicy]", "warning KY014"},
+		},
+		{
+			name:   "warnings are left out below the severity",
+			args:   []string{"-severity", "error", "-"},
+			stdin:  invalidPod,
+			code:   exitInvalid,
+			stdout: []string{"error KY003"},
+			absent: []string{"KY014"},
+		},
+		{
+			name:   "warnings alone don't fail",
+			args:   []string{"-severity", "error"},
+			stdin:  strings.Replace(invalidPod, "    imagePullPolicy: 3\n", "", 1),
+			code:   exitOK,
+			stdout: []string{"<stdin>: ok"},
+		},
+		{
+			name:   "only the bad document of a stream",
+			args:   []string{"-format", "json"},
+			stdin:  configMap + "---\nkind: Service\n",
+			code:   exitInvalid,
+			stdout: []string{`"Document": 1`, `"Code": "KY002"`, `"Valid": false`},
+		},
+		{
+			name:   "directory",
+			args:   []string{"-quiet", "-severity", "error", dir},
+			code:   exitInvalid,
+			stdout: []string{filepath.Join(dir, "pod.yml") + ":9:22: error KY003"},
+			absent: []string{"configmap.yaml", "notes.txt"},
+		},
+		{
+			name:   "version",
+			args:   []string{"-versions", "1.12"},
+			stdin:  configMap,
+			code:   exitOK,
+			stdout: []string{"<stdin>: ok"},
+		},
+		{
+			name:   "unknown version",
+			args:   []string{"-versions", "0.1"},
+			stdin:  configMap,
+			code:   exitError,
+			stderr: []string{`failed to get a resolver for version "0.1"`},
+		},
+		{
+			name:   "unknown severity",
+			args:   []string{"-severity", "bogus"},
+			code:   exitError,
+			stderr: []string{`unknown severity "bogus"`},
+		},
+		{
+			name:   "unknown flag",
+			args:   []string{"-bogus"},
+			code:   exitError,
+			stderr: []string{"usage: kubeyaml"},
+		},
+		{
+			name:   "missing file",
+			args:   []string{filepath.Join(dir, "missing.yaml")},
+			code:   exitError,
+			stderr: []string{"missing.yaml"},
+		},
+		{
+			name:   "not yaml",
+			stdin:  "a: [",
+			code:   exitError,
+			stderr: []string{"<stdin>: failed to unmarshal yaml"},
+		},
+		{
+			name:   "explain",
+			args:   []string{"explain", "apps/v1", "Deployment", "spec.replicas"},
+			code:   exitOK,
+			stdout: []string{"KIND:       Deployment", "FIELD:      replicas <integer>"},
+		},
+		{
+			name:   "explain an unknown field",
+			args:   []string{"explain", "apps/v1", "Deployment", "spec.replica"},
+			code:   exitInvalid,
+			stderr: []string{"replicas"},
+		},
+		{
+			name:   "explain without a kind",
+			args:   []string{"explain", "apps/v1"},
+			code:   exitError,
+			stderr: []string{"usage: kubeyaml explain"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			code := run(tc.args, strings.NewReader(tc.stdin), stdout, stderr)
+			if code != tc.code {
+				t.Fatalf("expected exit code %d but got %d\nstdout: %s\nstderr: %s", tc.code, code, stdout, stderr)
+			}
+			for _, s := range tc.stdout {
+				if !strings.Contains(stdout.String(), s) {
+					t.Errorf("expected stdout to contain %q but got %s", s, stdout)
+				}
+			}
+			for _, s := range tc.absent {
+				if strings.Contains(stdout.String(), s) {
+					t.Errorf("expected stdout not to contain %q but got %s", s, stdout)
+				}
+			}
+			for _, s := range tc.stderr {
+				if !strings.Contains(stderr.String(), s) {
+					t.Errorf("expected stderr to contain %q but got %s", s, stderr)
+				}
+			}
+		})
+	}
+}
@@ -13,12 +13,17 @@ import (
 
 	"github.com/chuckha/kubeyaml.com/backend/internal"
 	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/data"
 	"github.com/chuckha/kubeyaml.com/backend/internal/messages"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/deprecation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
 )
 
 type ServerArgs struct {
 	Port        string
 	Development bool
+	Versions    string
+	Severity    string
 }
 
 func main() {
@@ -27,6 +32,9 @@ func main() {
 	sa := &ServerArgs{}
 	fs.StringVar(&sa.Port, "port", "9000", "the port for the server to listen on")
 	fs.BoolVar(&sa.Development, "dev", false, "enable certain features when developing locally")
+	fs.StringVar(&sa.Versions, "versions", "", "comma separated list of kubernetes versions to validate against (default all available versions)")
+	// the frontend shows everything as an error so advice is left out unless asked for
+	fs.StringVar(&sa.Severity, "severity", string(problem.SeverityError), "the least severe problem to report when a request doesn't say: error, warning or info")
 
 	// Parse flags
 	if err := fs.Parse(os.Args[1:]); err != nil {
@@ -34,14 +42,37 @@ func main() {
 		os.Exit(1)
 	}
 
-	versions := []string{"1.15", "1.16", "1.17", "1.18"}
+	available, err := data.Versions()
+	if err != nil {
+		fmt.Printf("failed to list the available versions: %+v", err)
+		os.Exit(1)
+	}
+	versions := available
+	if sa.Versions != "" {
+		versions = strings.Split(sa.Versions, ",")
+	}
 	sortedVersions, err := internal.SortVersions(versions...)
 	if err != nil {
 		fmt.Printf("failed to sort versions: %+v", err)
 		os.Exit(1)
 	}
-	validators := make([]validator, len(versions))
-	for i, version := range versions {
+	if len(sortedVersions) == 0 {
+		fmt.Println("no kubernetes versions to validate against")
+		os.Exit(1)
+	}
+	severity, err := problem.ParseSeverity(sa.Severity)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	// every available version is compared to find deprecated and removed apiVersions, not just the ones validated against
+	deprecations, err := kubernetes.NewDeprecationIndex(available...)
+	if err != nil {
+		fmt.Printf("failed to compare the available versions: %+v", err)
+		os.Exit(1)
+	}
+	validators := make([]*kubernetes.Validator, len(sortedVersions))
+	for i, version := range sortedVersions {
 		resolver, err := kubernetes.NewResolver(version)
 		if err != nil {
 			fmt.Printf("failed to get a resolver for version %q: %v", version, err)
@@ -49,6 +80,10 @@ func main() {
 		}
 		validators[i] = kubernetes.NewValidator(resolver)
 	}
+	versionValidators := make([]kubernetes.VersionValidator, len(validators))
+	for i, v := range validators {
+		versionValidators[i] = v
+	}
 
 	loader := kubernetes.NewLoader()
 
@@ -59,16 +94,20 @@ func main() {
 	gf := kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io")
 
 	s := &server{
-		logger:     &log{os.Stdout},
-		validators: validators,
-		loader:     loader,
-		finder:     gf,
-		dev:        sa.Development,
-		versions:   computeVersionsResponse(sortedVersions),
+		logger:       &log{os.Stdout},
+		validators:   versionValidators,
+		explainer:    kubernetes.NewExplainer(gf, validators...),
+		loader:       loader,
+		finder:       gf,
+		deprecations: deprecations,
+		dev:          sa.Development,
+		severity:     severity,
+		versions:     computeVersionsResponse(sortedVersions),
 	}
 	mux := http.NewServeMux()
 	mux.HandleFunc("/validate", s.corsForDev(s.validate))
 	mux.HandleFunc("/versions", s.corsForDev(s.versionsHandler))
+	mux.HandleFunc("/explain", s.corsForDev(s.explain))
 	mux.HandleFunc("/favicon.ico", s.corsForDev(s.favicon))
 	mux.Handle("/static/", http.StripPrefix("/static", http.FileServer(http.Dir("static"))))
 	fmt.Printf("listening on port :%s\n", sa.Port)
@@ -98,14 +137,12 @@ func (l *log) Infof(format string, args ...interface{}) error {
 	return err
 }
 
-type validator interface {
-	Validate(map[interface{}]interface{}, *kubernetes.Schema) []error
-	Resolve(string) (*kubernetes.Schema, error)
-	Version() string
+type explainer interface {
+	Explain(version, apiVersion, kind string, path []string) (*kubernetes.Explanation, error)
 }
 
 type loader interface {
-	Load(io.Reader) (*kubernetes.Input, error)
+	Load(io.Reader) ([]*kubernetes.Input, error)
 }
 type groupFinder interface {
 	APIKey(string, string) string
@@ -113,11 +150,14 @@ type groupFinder interface {
 
 type server struct {
 	logger
-	validators []validator
-	loader     loader
-	finder     groupFinder
-	dev        bool
-	versions   []byte
+	validators   []kubernetes.VersionValidator
+	explainer    explainer
+	loader       loader
+	finder       groupFinder
+	deprecations *deprecation.Index
+	dev          bool
+	severity     problem.Severity
+	versions     []byte
 }
 
 func (s *server) favicon(w http.ResponseWriter, r *http.Request) {
@@ -154,53 +194,39 @@ func (s *server) validate(w http.ResponseWriter, r *http.Request) {
 		// Ignore empty requests
 		return
 	}
-	datar := strings.NewReader(data)
-	i, err := s.loader.Load(datar)
-	if err != nil {
-		switch err.(type) {
-		case *kubernetes.RequiredKeyNotFoundError:
-		case *kubernetes.YamlPathError:
-		default:
-			s.logger.Infof("error loading body with non user error: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-			return
-		}
-		errs := make(map[string][]error)
-		for _, v := range s.validators {
-			errs[v.Version()] = []error{err}
-		}
-
-		out, err := json.Marshal(errs)
+	severity := s.severity
+	if sv := v.Get("severity"); sv != "" {
+		severity, err = problem.ParseSeverity(sv)
 		if err != nil {
-			s.logger.Infof("error marshalling errors: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-
-		if _, err := w.Write(out); err != nil {
-			s.logger.Infof("error writing response body: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-		}
+	}
+	datar := strings.NewReader(data)
+	inputs, err := s.loader.Load(datar)
+	if err != nil {
+		s.logger.Infof("error loading body with non user error: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
-	defer r.Body.Close()
-
-	// Validate each version
-	errs := make(map[string][]error)
-	for _, v := range s.validators {
 
-		// Lookup the api group version to get started and ensure the kind is valid
-		// TODO: Consider making Resolve take a version and a kind so it can produce a better error message
-		schema, err := v.Resolve(s.finder.APIKey(i.APIVersion, i.Kind))
-		if err != nil {
-			errs[v.Version()] = []error{err}
-			continue
+	// Validate each document against each version
+	results := make([]messages.DocumentResult, len(inputs))
+	for i, input := range inputs {
+		problems := kubernetes.Check(input, s.finder, s.deprecations, s.validators...)
+		// problems with the document itself, such as duplicate keys and advice, are the same in every version
+		errs := make(map[string][]error)
+		for _, v := range s.validators {
+			found := append(problems.Versions[v.Version()], problems.Document...)
+			errs[v.Version()] = kubernetes.FilterSeverity(severity, found...)
 		}
-
-		errs[v.Version()] = v.Validate(i.Data, schema)
+		results[i] = messages.DocumentResult{Index: input.Index, Errors: errs}
 	}
+	s.writeResults(w, results)
+}
 
-	out, err := json.Marshal(errs)
+func (s *server) writeResults(w http.ResponseWriter, results []messages.DocumentResult) {
+	out, err := json.Marshal(results)
 	if err != nil {
 		s.logger.Infof("error marshalling errors: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
@@ -213,6 +239,49 @@ func (s *server) validate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// explain documents a field of an object, such as `/explain?version=1.12&apiVersion=apps/v1&kind=Deployment&path=spec.strategy`.
+// The version defaults to the newest one and the path to the object itself.
+func (s *server) explain(w http.ResponseWriter, r *http.Request) {
+	s.logRequest("explain", r)
+
+	if r.Method != "GET" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	apiVersion, kind := q.Get("apiVersion"), q.Get("kind")
+	if apiVersion == "" || kind == "" {
+		http.Error(w, "apiVersion and kind are required", http.StatusBadRequest)
+		return
+	}
+	var path []string
+	if p := strings.Trim(q.Get("path"), "."); p != "" {
+		path = strings.Split(p, ".")
+	}
+
+	explanation, err := s.explainer.Explain(q.Get("version"), apiVersion, kind, path)
+	if err != nil {
+		if _, ok := err.(*kubernetes.UnknownVersionError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// either the kind or one of the keys in the path doesn't exist
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	out, err := json.Marshal(explanation)
+	if err != nil {
+		s.logger.Infof("error marshalling explanation: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(out); err != nil {
+		s.logger.Infof("error writing response body: %v\n", err)
+	}
+}
+
 func (s *server) versionsHandler(w http.ResponseWriter, r *http.Request) {
 	if _, err := w.Write(s.versions); err != nil {
 		s.logger.Infof("error writing response body: %v\n", err)
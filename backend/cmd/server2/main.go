@@ -1,12 +1,102 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal"
 	"github.com/chuckha/kubeyaml.com/backend/internal/adapters/web"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/data"
 	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
 )
 
 func main() {
-	svc := validation.NewService()
-	svr := web.NewServer(svc, web.WithDevMode(true))
+	crds := flag.String("crds", "", "comma separated list of files or directories with custom resource definitions to validate against")
+	openapi := flag.String("openapi-v3", "", "comma separated list of version=path pairs, each path an OpenAPI v3 document or a directory of them, such as 1.27=./openapi")
+	flag.Parse()
+
+	opts := make([]validation.ServiceOption, 0)
+	if *openapi != "" {
+		for _, pair := range strings.Split(*openapi, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 {
+				fmt.Printf("expected version=path but got %q\n", pair)
+				os.Exit(1)
+			}
+			swagger, err := validation.LoadOpenAPIV3Path(parts[1])
+			if err != nil {
+				fmt.Printf("failed to load openapi documents: %v\n", err)
+				os.Exit(1)
+			}
+			opts = append(opts, validation.WithSwagger(parts[0], swagger))
+		}
+	}
+	var registry *crd.Registry
+	if *crds != "" {
+		registry = crd.NewRegistry()
+		for _, path := range strings.Split(*crds, ",") {
+			if err := registry.LoadPath(path); err != nil {
+				fmt.Printf("failed to load custom resource definitions: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		custom, err := validation.NewCustomResources(registry)
+		if err != nil {
+			fmt.Printf("failed to load custom resource definitions: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, validation.WithCustomResources(custom))
+	}
+
+	svc, err := validation.NewService(opts...)
+	if err != nil {
+		fmt.Printf("failed to create the validation service: %v\n", err)
+		os.Exit(1)
+	}
+	explainer, err := newExplainer(registry)
+	if err != nil {
+		fmt.Printf("failed to create the explainer: %v\n", err)
+		os.Exit(1)
+	}
+	svr := web.NewServer(svc, web.WithDevMode(true), web.WithExplainer(explainer))
 	svr.Run()
 }
+
+// newExplainer explains the fields of the bundled versions, newest first, and of the custom resources in the registry.
+func newExplainer(registry *crd.Registry) (*kubernetes.Explainer, error) {
+	available, err := data.Versions()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := internal.SortVersions(available...)
+	if err != nil {
+		return nil, err
+	}
+	var custom *kubernetes.CustomResources
+	if registry != nil {
+		custom, err = kubernetes.NewCustomResources(registry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	finder := kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io")
+	validators := make([]*kubernetes.Validator, len(versions))
+	for i, version := range versions {
+		resolver, err := kubernetes.NewResolver(version)
+		if err != nil {
+			return nil, err
+		}
+		if custom != nil {
+			resolver.AddCustomResources(custom)
+		}
+		validators[i] = kubernetes.NewValidator(resolver)
+	}
+	if custom != nil {
+		finder.AddCustomResources(custom)
+	}
+	return kubernetes.NewExplainer(finder, validators...), nil
+}
@@ -1,8 +1,9 @@
 module github.com/chuckha/kubeyaml.com/backend
 
-go 1.13
+go 1.16
 
 require (
 	github.com/pkg/errors v0.9.1
 	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
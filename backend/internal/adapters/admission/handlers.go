@@ -0,0 +1,105 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
+)
+
+func (s *Server) validate(w http.ResponseWriter, r *http.Request) {
+	s.log.Infof("[validate]: %s %s\n", r.Method, r.URL.Path)
+
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.log.Infof("error reading body: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	review := &AdmissionReview{}
+	if err := json.Unmarshal(b, review); err != nil {
+		http.Error(w, fmt.Sprintf("invalid admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.APIVersion != reviewAPIVersion || review.Kind != reviewKind || review.Request == nil {
+		http.Error(w, fmt.Sprintf("expected an %s %s with a request", reviewAPIVersion, reviewKind), http.StatusBadRequest)
+		return
+	}
+
+	out, err := json.Marshal(&AdmissionReview{
+		APIVersion: reviewAPIVersion,
+		Kind:       reviewKind,
+		Response:   s.review(review.Request),
+	})
+	if err != nil {
+		s.log.Infof("error marshalling admission review: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(out); err != nil {
+		s.log.Infof("error writing response body: %v\n", err)
+	}
+}
+
+// review denies objects with errors. Warnings are passed on to the client and info is left out.
+// Objects kubeyaml has no schema for, such as custom resources it wasn't told about, are allowed with a warning
+// so installing the webhook doesn't block everything else. So are unknown keys when the cluster is newer than the schema.
+func (s *Server) review(req *AdmissionRequest) *AdmissionResponse {
+	resp := &AdmissionResponse{UID: req.UID, Allowed: true}
+	if len(req.Object) == 0 || req.Operation == "DELETE" || req.Operation == "CONNECT" {
+		return resp
+	}
+
+	// JSON is YAML so the object is validated as it is
+	result, err := s.svc.Validate(req.Object, s.version)
+	if err != nil {
+		s.log.Infof("error validating %s %s/%s: %v\n", req.Kind.Kind, req.Namespace, req.Name, err)
+		resp.Warnings = []string{fmt.Sprintf("kubeyaml could not validate this object: %v", err)}
+		return resp
+	}
+	denied := make([]string, 0)
+	for _, document := range result.Documents {
+		for _, detail := range document.Errors[s.version] {
+			message := detail.Message
+			if detail.Path != "" {
+				message = fmt.Sprintf("[%s] %s", detail.Path, detail.Message)
+			}
+			switch {
+			case detail.Kind == problem.KindUnknownSchema:
+				resp.Warnings = append(resp.Warnings, "kubeyaml: "+message)
+			case detail.Kind == problem.KindUnknownKey && s.newerCluster:
+				resp.Warnings = append(resp.Warnings, fmt.Sprintf("kubeyaml: %s (the schema is for kubernetes %s, which may not have this field)", message, s.version))
+			case detail.Severity.AtLeast(problem.SeverityError):
+				denied = append(denied, message)
+			case detail.Severity.AtLeast(problem.SeverityWarning):
+				resp.Warnings = append(resp.Warnings, "kubeyaml: "+message)
+			}
+		}
+	}
+	if len(denied) == 0 {
+		return resp
+	}
+	resp.Allowed = false
+	resp.Result = &Status{
+		Status:  "Failure",
+		Message: fmt.Sprintf("kubeyaml denied the request: %s", strings.Join(denied, ", ")),
+		Reason:  "Invalid",
+		Code:    http.StatusUnprocessableEntity,
+	}
+	return resp
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
@@ -0,0 +1,147 @@
+package admission
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/logging"
+)
+
+func TestReview(t *testing.T) {
+	svc, err := validation.NewService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(svc, WithVersion("1.12"))
+	s.log = &logging.Log{Writer: ioutil.Discard}
+
+	testcases := []struct {
+		name      string
+		operation string
+		object    string
+		allowed   bool
+		// message is part of the reason a request is denied.
+		message  string
+		warnings int
+	}{
+		{
+			name:      "valid",
+			operation: "CREATE",
+			object:    `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "prod"}}`,
+			allowed:   true,
+		},
+		{
+			name:      "wrong type",
+			operation: "UPDATE",
+			object:    `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "spec": {"replicas": "three", "selector": {}, "template": {}}}`,
+			message:   "[spec.replicas] key replicas has wrong type string (should be integer)",
+		},
+		{
+			name:      "warnings are passed on",
+			operation: "CREATE",
+			object:    `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web"}, "spec": {"containers": [{"name": "web", "image": "nginx"}]}}`,
+			allowed:   true,
+			warnings:  2,
+		},
+		{
+			name:      "unknown kinds are allowed",
+			operation: "CREATE",
+			object:    `{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"name": "a"}}`,
+			allowed:   true,
+			warnings:  1,
+		},
+		{
+			name:      "deletes are allowed",
+			operation: "DELETE",
+			allowed:   true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := &AdmissionReview{
+				APIVersion: reviewAPIVersion,
+				Kind:       reviewKind,
+				Request: &AdmissionRequest{
+					UID:       "705ab4f5-6393-11e8-b7cc-42010a800002",
+					Operation: tc.operation,
+				},
+			}
+			if tc.object != "" {
+				request.Request.Object = json.RawMessage(tc.object)
+			}
+			b, err := json.Marshal(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			s.svr.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/validate", strings.NewReader(string(b))))
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected a 200 but got %d: %s", w.Code, w.Body)
+			}
+
+			review := &AdmissionReview{}
+			if err := json.Unmarshal(w.Body.Bytes(), review); err != nil {
+				t.Fatal(err)
+			}
+			resp := review.Response
+			if review.APIVersion != reviewAPIVersion || review.Kind != reviewKind || resp == nil || resp.UID != request.Request.UID {
+				t.Fatalf("expected a response to the review but got %s", w.Body)
+			}
+			if resp.Allowed != tc.allowed {
+				t.Fatalf("expected allowed to be %v but got %s", tc.allowed, w.Body)
+			}
+			if !tc.allowed && (resp.Result == nil || !strings.Contains(resp.Result.Message, tc.message)) {
+				t.Fatalf("expected the reason to contain %q but got %s", tc.message, w.Body)
+			}
+			if len(resp.Warnings) != tc.warnings {
+				t.Fatalf("expected %d warnings but got %v", tc.warnings, resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestReviewNewerCluster(t *testing.T) {
+	svc, err := validation.NewService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a field added after the schema, and a wrong type that is an error in every version
+	unknownKey := `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "prod"}, "spec": {"newField": true}}`
+	wrongType := `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "prod"}, "spec": {"finalizers": "kubernetes"}}`
+
+	strict := NewServer(svc, WithVersion("1.12"))
+	strict.log = &logging.Log{Writer: ioutil.Discard}
+	if resp := strict.review(&AdmissionRequest{Operation: "CREATE", Object: json.RawMessage(unknownKey)}); resp.Allowed {
+		t.Fatalf("expected an unknown key to be denied but got %+v", resp)
+	}
+
+	lenient := NewServer(svc, WithVersion("1.12"), WithNewerCluster())
+	lenient.log = &logging.Log{Writer: ioutil.Discard}
+	resp := lenient.review(&AdmissionRequest{Operation: "CREATE", Object: json.RawMessage(unknownKey)})
+	if !resp.Allowed || len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "spec.newField") {
+		t.Fatalf("expected an unknown key to be allowed with a warning but got %+v", resp)
+	}
+	if resp := lenient.review(&AdmissionRequest{Operation: "CREATE", Object: json.RawMessage(wrongType)}); resp.Allowed {
+		t.Fatalf("expected a wrong type to be denied but got %+v", resp)
+	}
+}
+
+func TestReviewRejectsOtherVersions(t *testing.T) {
+	svc, err := validation.NewService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(svc)
+	s.log = &logging.Log{Writer: ioutil.Discard}
+	w := httptest.NewRecorder()
+	body := `{"apiVersion": "admission.k8s.io/v1beta1", "kind": "AdmissionReview", "request": {"uid": "a"}}`
+	s.svr.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/validate", strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a 400 but got %d", w.Code)
+	}
+}
@@ -0,0 +1,55 @@
+package admission
+
+import "encoding/json"
+
+// These are the parts of admission.k8s.io/v1 that a validating webhook needs.
+// They are copied rather than imported so kubeyaml doesn't depend on k8s.io/api.
+const (
+	reviewAPIVersion = "admission.k8s.io/v1"
+	reviewKind       = "AdmissionReview"
+)
+
+// AdmissionReview is what the API server sends to a webhook and what the webhook replies with.
+type AdmissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *AdmissionRequest  `json:"request,omitempty"`
+	Response   *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest is the object being admitted and why.
+type AdmissionRequest struct {
+	UID       string           `json:"uid"`
+	Kind      GroupVersionKind `json:"kind"`
+	Name      string           `json:"name,omitempty"`
+	Namespace string           `json:"namespace,omitempty"`
+	// Operation is CREATE, UPDATE, DELETE or CONNECT.
+	Operation string `json:"operation"`
+	// Object is the new object, it's empty for DELETE.
+	Object json.RawMessage `json:"object,omitempty"`
+	DryRun *bool           `json:"dryRun,omitempty"`
+}
+
+// GroupVersionKind is the type of the object being admitted.
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// AdmissionResponse allows or denies a request.
+type AdmissionResponse struct {
+	UID     string  `json:"uid"`
+	Allowed bool    `json:"allowed"`
+	Result  *Status `json:"status,omitempty"`
+	// Warnings are shown to the client, such as kubectl, even when the request is allowed.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Status is the part of a metav1.Status the API server shows when a request is denied.
+type Status struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Code    int32  `json:"code"`
+}
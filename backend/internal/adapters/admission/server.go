@@ -0,0 +1,102 @@
+// Package admission runs the validator as a kubernetes ValidatingAdmissionWebhook.
+// The API server sends every object it is about to admit, objects with errors are denied.
+//
+// Objects are validated against the schemas of the cluster's kubernetes version. A cluster newer than every schema
+// kubeyaml has is validated against the newest one with WithNewerCluster, and fields added since then are only warned about.
+package admission
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/logging"
+)
+
+const (
+	defaultAddr = ":8443"
+)
+
+type service interface {
+	Validate(input []byte, versions ...string) (*validation.Result, error)
+	Versions() []string
+}
+
+type logger interface {
+	Infof(a string, args ...interface{})
+}
+
+type Server struct {
+	svr *http.Server
+	svc service
+	log logger
+	// version is the kubernetes version of the cluster, objects are validated against its schemas.
+	version string
+	// newerCluster is true when the cluster is newer than version, unknown keys may be fields added since.
+	newerCluster bool
+	certFile     string
+	keyFile      string
+}
+
+type ServerOption func(s *Server)
+
+func WithAddr(addr string) ServerOption {
+	return func(s *Server) {
+		s.svr.Addr = addr
+	}
+}
+
+// WithVersion validates against the schemas of the cluster's kubernetes version instead of the newest one.
+func WithVersion(version string) ServerOption {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// WithNewerCluster says the cluster is newer than the version validated against.
+// Fields added to the API since then look like unknown keys, so unknown keys are passed on as warnings instead of denying requests.
+// Every other error still denies them.
+func WithNewerCluster() ServerOption {
+	return func(s *Server) {
+		s.newerCluster = true
+	}
+}
+
+// WithTLS serves with the certificate and key in the files. The API server only calls webhooks over TLS.
+func WithTLS(certFile, keyFile string) ServerOption {
+	return func(s *Server) {
+		s.certFile = certFile
+		s.keyFile = keyFile
+	}
+}
+
+func NewServer(svc service, opts ...ServerOption) *Server {
+	s := &Server{
+		svc: svc,
+		log: &logging.Log{Writer: os.Stdout},
+	}
+	if versions := svc.Versions(); len(versions) > 0 {
+		s.version = versions[0]
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validate", s.validate)
+	mux.HandleFunc("/healthz", s.healthz)
+	s.svr = &http.Server{
+		Addr:    defaultAddr,
+		Handler: mux,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Run serves webhook requests until the server fails.
+func (s *Server) Run() error {
+	if s.certFile == "" || s.keyFile == "" {
+		return errors.New("a certificate and key are required, the API server only calls webhooks over TLS")
+	}
+	s.log.Infof("Serving admission reviews on %s for kubernetes %s\n", s.svr.Addr, s.version)
+	return s.svr.ListenAndServeTLS(s.certFile, s.keyFile)
+}
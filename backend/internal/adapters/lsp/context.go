@@ -0,0 +1,182 @@
+package lsp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Hover and completion happen while a document is being typed, when it usually doesn't parse.
+// Where the cursor is gets worked out from indentation alone instead.
+
+// line is a line of YAML broken into the parts that tell where it is in a document.
+type line struct {
+	// dashes are the columns of the `-` that start sequence items.
+	dashes []int
+	// column is where the content after the indentation and any dashes starts.
+	column int
+	// key is the mapping key on the line, it's empty when there isn't one.
+	key string
+	// value is everything after the key, without comments.
+	value string
+	// blank is true for lines with nothing but whitespace or a comment.
+	blank bool
+}
+
+func parseLine(text string) *line {
+	text = strings.TrimRight(text, "\r")
+	l := &line{}
+	i := 0
+	for {
+		for i < len(text) && text[i] == ' ' {
+			i++
+		}
+		if i < len(text) && text[i] == '-' && (i+1 == len(text) || text[i+1] == ' ') {
+			l.dashes = append(l.dashes, i)
+			i++
+			continue
+		}
+		break
+	}
+	l.column = i
+	rest := text[i:]
+	if rest == "" || rest[0] == '#' {
+		l.blank = len(l.dashes) == 0
+		return l
+	}
+	colon := keyEnd(rest)
+	if colon < 0 {
+		return l
+	}
+	l.key = unquote(strings.TrimSpace(rest[:colon]))
+	l.value = rest[colon+1:]
+	if j := strings.Index(l.value, " #"); j >= 0 {
+		l.value = l.value[:j]
+	}
+	l.value = unquote(strings.TrimSpace(l.value))
+	return l
+}
+
+// keyEnd is the index of the colon that ends the key at the start of s, or -1 if s doesn't start with a key.
+func keyEnd(s string) int {
+	from := 0
+	switch s[0] {
+	case '{', '[', '|', '>', '&', '*', '!':
+		return -1
+	case '"', '\'':
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 {
+			return -1
+		}
+		from = end + 2
+	}
+	for i := from; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ') {
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func isSeparator(text string) bool {
+	return strings.HasPrefix(text, "---") || strings.TrimRight(text, "\r") == "..."
+}
+
+// parents is the path of the mapping that holds the content of current, which is on line n.
+// Sequence items get their index, such as `spec.containers.1` for a key in the second container.
+func parents(lines []string, n int, current *line) []string {
+	reversed := make([]string, 0)
+	column := current.column
+	// item is the position in reversed of the index of the sequence item being looked at, or -1 outside of items.
+	item, index := -1, 0
+	enterItem := func(l *line) {
+		if len(l.dashes) == 0 {
+			return
+		}
+		item, index = len(reversed), 0
+		reversed = append(reversed, "")
+		column = l.dashes[len(l.dashes)-1]
+	}
+	enterItem(current)
+
+	for i := n - 1; i >= 0; i-- {
+		if isSeparator(lines[i]) {
+			break
+		}
+		l := parseLine(lines[i])
+		if l.blank {
+			continue
+		}
+		if item >= 0 {
+			// look for the key of the sequence, counting the items before this one on the way
+			if len(l.dashes) > 0 && l.dashes[len(l.dashes)-1] == column {
+				index++
+				continue
+			}
+			if l.column > column || l.key == "" {
+				continue
+			}
+			reversed[item] = strconv.Itoa(index)
+			item = -1
+			reversed = append(reversed, l.key)
+			column = l.column
+			enterItem(l)
+			continue
+		}
+		// look for the key of the mapping
+		if l.column > column {
+			continue
+		}
+		if l.column == column {
+			// a sibling, which might be the first key of the sequence item we are in
+			enterItem(l)
+			continue
+		}
+		if l.key == "" {
+			continue
+		}
+		reversed = append(reversed, l.key)
+		column = l.column
+		enterItem(l)
+	}
+
+	path := make([]string, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		// an item without a sequence key isn't something a kubernetes object has
+		if reversed[i] != "" {
+			path = append(path, reversed[i])
+		}
+	}
+	return path
+}
+
+// header finds the apiVersion and kind of the document that line n is in.
+func header(lines []string, n int) (string, string) {
+	start := n
+	for start > 0 && !isSeparator(lines[start]) {
+		start--
+	}
+	var apiVersion, kind string
+	for i := start; i < len(lines); i++ {
+		if i > start && isSeparator(lines[i]) {
+			break
+		}
+		l := parseLine(lines[i])
+		if l.column != 0 || len(l.dashes) > 0 {
+			continue
+		}
+		switch l.key {
+		case "apiVersion":
+			apiVersion = l.value
+		case "kind":
+			kind = l.value
+		}
+	}
+	return apiVersion, kind
+}
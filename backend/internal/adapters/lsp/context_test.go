@@ -0,0 +1,91 @@
+package lsp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.15
+      - name: sidecar
+        ports:
+        - containerPort: 80
+          # a comment
+
+        resources: {}
+---
+apiVersion: v1
+kind: Service
+`
+
+func TestParents(t *testing.T) {
+	testcases := []struct {
+		name     string
+		line     int
+		expected []string
+	}{
+		{
+			name:     "top level",
+			line:     2,
+			expected: []string{},
+		},
+		{
+			name:     "mapping",
+			line:     5,
+			expected: []string{"spec"},
+		},
+		{
+			name:     "first key of a sequence item",
+			line:     8,
+			expected: []string{"spec", "template", "spec", "containers", "0"},
+		},
+		{
+			name:     "second sequence item",
+			line:     11,
+			expected: []string{"spec", "template", "spec", "containers", "1"},
+		},
+		{
+			name:     "nested sequence",
+			line:     12,
+			expected: []string{"spec", "template", "spec", "containers", "1", "ports", "0"},
+		},
+		{
+			name:     "after blank lines and comments",
+			line:     15,
+			expected: []string{"spec", "template", "spec", "containers", "1"},
+		},
+		{
+			name:     "next document",
+			line:     18,
+			expected: []string{},
+		},
+	}
+	lines := strings.Split(deployment, "\n")
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parents(lines, tc.line, parseLine(lines[tc.line]))
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	lines := strings.Split(deployment, "\n")
+	if apiVersion, kind := header(lines, 12); apiVersion != "apps/v1" || kind != "Deployment" {
+		t.Fatalf("expected apps/v1 Deployment but got %s %s", apiVersion, kind)
+	}
+	if apiVersion, kind := header(lines, 18); apiVersion != "v1" || kind != "Service" {
+		t.Fatalf("expected v1 Service but got %s %s", apiVersion, kind)
+	}
+}
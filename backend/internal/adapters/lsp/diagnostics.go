@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/position"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
+)
+
+const diagnosticSource = "kubeyaml"
+
+// syntaxErrorLine finds the line in the errors of the yaml parser, such as `yaml: line 3: mapping values are not allowed in this context`.
+var syntaxErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// diagnose validates every document in text against every version.
+// A problem found in more than one version is a single diagnostic that lists the versions.
+func (s *Server) diagnose(text string) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0)
+	inputs, err := s.loader.Load(strings.NewReader(text))
+	if err != nil {
+		return append(diagnostics, syntaxError(err))
+	}
+
+	// versions of every diagnostic, in the order the diagnostics were found
+	versions := make(map[string][]string)
+	order := make([]string, 0)
+	found := make(map[string]Diagnostic)
+	lines := strings.Split(text, "\n")
+	add := func(version string, errs ...error) {
+		for _, err := range errs {
+			d := diagnostic(lines, kubernetes.DetailOf(err))
+			key := fmt.Sprintf("%v %s %s", d.Range, d.Code, d.Message)
+			if _, ok := found[key]; !ok {
+				order = append(order, key)
+				found[key] = d
+			}
+			if version != "" {
+				versions[key] = append(versions[key], version)
+			}
+		}
+	}
+	validators := make([]kubernetes.VersionValidator, len(s.validators))
+	for i, v := range s.validators {
+		validators[i] = v
+	}
+	for _, input := range inputs {
+		problems := kubernetes.Check(input, s.finder, s.deprecations, validators...)
+		add("", problems.Document...)
+		for _, v := range s.validators {
+			add(v.Version(), problems.Versions[v.Version()]...)
+		}
+	}
+
+	for _, key := range order {
+		d := found[key]
+		// the version only matters when there is more than one
+		if len(s.validators) > 1 && len(versions[key]) > 0 {
+			d.Message = fmt.Sprintf("%s (%s)", d.Message, strings.Join(versions[key], ", "))
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+func diagnostic(lines []string, detail *problem.Detail) Diagnostic {
+	severity := severityError
+	switch detail.Severity {
+	case problem.SeverityWarning:
+		severity = severityWarning
+	case problem.SeverityInfo:
+		severity = severityInformation
+	}
+	return Diagnostic{
+		Range:    rangeOf(lines, detail.Position),
+		Severity: severity,
+		Code:     string(detail.Code),
+		Source:   diagnosticSource,
+		Message:  detail.Message,
+	}
+}
+
+// rangeOf converts a position, which starts at 1 and counts runes, to a range, which starts at 0 and counts UTF-16 code units.
+// Problems without a position go at the top.
+func rangeOf(lines []string, p *position.Position) Range {
+	if p == nil {
+		return Range{}
+	}
+	return Range{
+		Start: Position{Line: p.Line - 1, Character: characterAt(lines, p.Line-1, p.Column-1)},
+		End:   Position{Line: p.EndLine - 1, Character: characterAt(lines, p.EndLine-1, p.EndColumn-1)},
+	}
+}
+
+// characterAt is the UTF-16 offset of column on line n, columns of lines that aren't in the document are passed through.
+func characterAt(lines []string, n, column int) int {
+	if n < 0 || n >= len(lines) {
+		return column
+	}
+	return character(lines[n], column)
+}
+
+// syntaxError covers the whole line the yaml parser stopped at.
+func syntaxError(err error) Diagnostic {
+	d := Diagnostic{Severity: severityError, Code: string(problem.CodeUnknown), Source: diagnosticSource, Message: err.Error()}
+	if m := syntaxErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+			d.Range = Range{Start: Position{Line: n - 1}, End: Position{Line: n}}
+		}
+	}
+	return d
+}
@@ -0,0 +1,168 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+)
+
+func (s *Server) handle(req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		s.initialized = true
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:   &TextDocumentSyncOptions{OpenClose: true, Change: textDocumentSyncFull},
+				HoverProvider:      true,
+				CompletionProvider: &CompletionOptions{},
+			},
+			ServerInfo: &ServerInfo{Name: serverName},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		params := &DidOpenTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return nil, err
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, s.publish(params.TextDocument.URI)
+	case "textDocument/didChange":
+		params := &DidChangeTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// every change is the whole document so the last one is all that matters
+		s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.publish(params.TextDocument.URI)
+	case "textDocument/didClose":
+		params := &DidCloseTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		// clear the diagnostics of the closed document
+		return nil, s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	case "textDocument/hover":
+		params := &TextDocumentPositionParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+	case "textDocument/completion":
+		params := &TextDocumentPositionParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return nil, err
+		}
+		return s.complete(params), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+}
+
+func (s *Server) publish(uri string) error {
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: s.diagnose(s.documents[uri]),
+	})
+}
+
+// hover describes the key on the line under the cursor, the cursor can be anywhere on the line.
+func (s *Server) hover(params *TextDocumentPositionParams) *Hover {
+	lines, ok := s.lines(params.TextDocument.URI, params.Position.Line)
+	if !ok {
+		return nil
+	}
+	n := params.Position.Line
+	current := parseLine(lines[n])
+	if current.key == "" {
+		return nil
+	}
+	path := append(parents(lines, n, current), current.key)
+	field, err := s.lookup(lines, n, path)
+	if err != nil {
+		return nil
+	}
+	title := fmt.Sprintf("**%s** `%s`", current.key, field.Type())
+	if field.Required {
+		title += " (required)"
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: markupKindMarkdown, Value: strings.TrimSpace(title + "\n\n" + field.Description())},
+		Range: &Range{
+			Start: Position{Line: n, Character: current.column},
+			End:   Position{Line: n, Character: current.column + len(utf16.Encode([]rune(current.key)))},
+		},
+	}
+}
+
+// complete lists the keys that can go where the cursor is. Nothing is completed once the key has a colon.
+func (s *Server) complete(params *TextDocumentPositionParams) *CompletionList {
+	list := &CompletionList{Items: []CompletionItem{}}
+	lines, ok := s.lines(params.TextDocument.URI, params.Position.Line)
+	if !ok {
+		return list
+	}
+	n := params.Position.Line
+	text := lines[n]
+	text = text[:byteOffset(text, params.Position.Character)]
+	current := parseLine(text)
+	if current.key != "" || strings.HasPrefix(strings.TrimSpace(text[current.column:]), "#") {
+		return list
+	}
+	field, err := s.lookup(lines, n, parents(lines, n, current))
+	if err != nil {
+		return list
+	}
+	fields, err := s.validators[0].Fields(field)
+	if err != nil {
+		return list
+	}
+	for _, f := range fields {
+		item := CompletionItem{
+			Label:      f.Name,
+			Kind:       completionItemKindProperty,
+			Detail:     f.Type(),
+			InsertText: f.Name + ": ",
+		}
+		if f.Required {
+			item.Detail += " (required)"
+		}
+		if description := f.Description(); description != "" {
+			item.Documentation = &MarkupContent{Kind: markupKindMarkdown, Value: description}
+		}
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+// lines splits an open document into lines, it's false when the document isn't open or doesn't have line n.
+func (s *Server) lines(uri string, n int) ([]string, bool) {
+	text, ok := s.documents[uri]
+	if !ok {
+		return nil, false
+	}
+	lines := strings.Split(text, "\n")
+	if n < 0 || n >= len(lines) {
+		return nil, false
+	}
+	return lines, true
+}
+
+// lookup finds the field at path in the schema of the document that line n is in.
+func (s *Server) lookup(lines []string, n int, path []string) (*kubernetes.Field, error) {
+	if len(s.validators) == 0 {
+		return nil, fmt.Errorf("no versions to look fields up in")
+	}
+	apiVersion, kind := header(lines, n)
+	if apiVersion == "" || kind == "" {
+		return nil, fmt.Errorf("document has no apiVersion or kind")
+	}
+	return s.validators[0].Lookup(s.finder.APIKey(apiVersion, kind), path)
+}
@@ -0,0 +1,139 @@
+// Package lsp runs the validator as a Language Server Protocol server over stdio.
+// Editors get diagnostics as documents change, hover text for keys and completion of keys.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/deprecation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/logging"
+)
+
+const serverName = "kubeyaml"
+
+type logger interface {
+	Infof(a string, args ...interface{})
+}
+
+type Server struct {
+	loader *kubernetes.Loader
+	finder *kubernetes.APIKeyer
+	// validators are newest first, hover and completion use the first one.
+	validators   []*kubernetes.Validator
+	deprecations *deprecation.Index
+	log          logger
+
+	conn *conn
+	// documents are the open documents by URI.
+	documents   map[string]string
+	initialized bool
+	shutdown    bool
+}
+
+type ServerOption func(s *Server)
+
+// WithDeprecations reports deprecated and removed apiVersions found in the index.
+func WithDeprecations(index *deprecation.Index) ServerOption {
+	return func(s *Server) {
+		s.deprecations = index
+	}
+}
+
+// NewServer returns a server that validates against every validator. stdout is the protocol so logs go to stderr.
+func NewServer(finder *kubernetes.APIKeyer, validators []*kubernetes.Validator, opts ...ServerOption) *Server {
+	s := &Server{
+		loader:     kubernetes.NewLoader(),
+		finder:     finder,
+		validators: validators,
+		log:        &logging.Log{Writer: os.Stderr},
+		documents:  make(map[string]string),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// errExit is returned by the exit notification.
+var errExit = errors.New("exit")
+
+// Run serves requests read from in until the client says to exit.
+// It returns an error if the client goes away or exits without shutting the server down first.
+func (s *Server) Run(in io.Reader, out io.Writer) error {
+	s.conn = newConn(in, out)
+	for {
+		body, err := s.conn.read()
+		if err != nil {
+			return fmt.Errorf("failed to read a message: %v", err)
+		}
+		if err := s.serve(body); err != nil {
+			if err != errExit {
+				return err
+			}
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+	}
+}
+
+// serve handles a single message and replies to it if it's a request.
+func (s *Server) serve(body []byte) error {
+	req := &request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return s.reply(json.RawMessage("null"), nil, &responseError{Code: codeParseError, Message: err.Error()})
+	}
+	if req.Method == "" {
+		// a response to a request, the server doesn't send any
+		if req.isNotification() {
+			return nil
+		}
+		return s.reply(req.ID, nil, &responseError{Code: codeInvalidRequest, Message: "missing method"})
+	}
+	if req.Method == "exit" {
+		return errExit
+	}
+	if !s.initialized && req.Method != "initialize" {
+		if req.isNotification() {
+			return nil
+		}
+		return s.reply(req.ID, nil, &responseError{Code: codeServerNotInitialized, Message: "the server is not initialized"})
+	}
+
+	result, err := s.handle(req)
+	if req.isNotification() {
+		if err != nil {
+			s.log.Infof("error handling %s: %v\n", req.Method, err)
+		}
+		return nil
+	}
+	return s.reply(req.ID, result, err)
+}
+
+func (s *Server) reply(id json.RawMessage, result interface{}, err error) error {
+	resp := &response{JSONRPC: jsonrpcVersion, ID: id}
+	if err != nil {
+		respErr, ok := err.(*responseError)
+		if !ok {
+			respErr = &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		resp.Error = respErr
+		return s.conn.write(resp)
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	resp.Result = out
+	return s.conn.write(resp)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.conn.write(&notification{JSONRPC: jsonrpcVersion, Method: method, Params: params})
+}
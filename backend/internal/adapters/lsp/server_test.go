@@ -0,0 +1,221 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/logging"
+)
+
+const (
+	uri = "file:///pod.yaml"
+	pod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx:1.15
+    imagePullPolicy: 3
+    
+`
+)
+
+// session frames every message the way an editor would and returns what the server wrote back.
+func session(t *testing.T, messages ...interface{}) []map[string]json.RawMessage {
+	t.Helper()
+	resolver, err := kubernetes.NewResolver("1.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io"), []*kubernetes.Validator{kubernetes.NewValidator(resolver)})
+	s.log = &logging.Log{Writer: ioutil.Discard}
+
+	in := &bytes.Buffer{}
+	w := newConn(nil, in)
+	for _, m := range messages {
+		if err := w.write(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := &bytes.Buffer{}
+	if err := s.Run(in, out); err != nil {
+		t.Fatal(err)
+	}
+
+	replies := make([]map[string]json.RawMessage, 0)
+	r := newConn(out, nil)
+	for {
+		body, err := r.read()
+		if err == io.EOF {
+			return replies
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply := map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &reply); err != nil {
+			t.Fatal(err)
+		}
+		replies = append(replies, reply)
+	}
+}
+
+func call(id int, method string, params interface{}) interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+}
+
+func at(line, character int) interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": line, "character": character},
+	}
+}
+
+func TestSession(t *testing.T) {
+	replies := session(t,
+		call(1, "initialize", map[string]interface{}{}),
+		map[string]interface{}{"jsonrpc": "2.0", "method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "yaml", "version": 1, "text": pod},
+		}},
+		call(2, "textDocument/hover", at(8, 6)),
+		call(3, "textDocument/completion", at(9, 4)),
+		call(4, "textDocument/definition", at(0, 0)),
+		call(5, "shutdown", nil),
+		map[string]interface{}{"jsonrpc": "2.0", "method": "exit"},
+	)
+	if len(replies) != 6 {
+		t.Fatalf("expected 5 responses and a notification but got %d", len(replies))
+	}
+
+	initialize := &InitializeResult{}
+	if err := json.Unmarshal(replies[0]["result"], initialize); err != nil {
+		t.Fatal(err)
+	}
+	if !initialize.Capabilities.HoverProvider || initialize.Capabilities.TextDocumentSync.Change != textDocumentSyncFull {
+		t.Fatalf("unexpected capabilities %s", replies[0]["result"])
+	}
+
+	diagnostics := &PublishDiagnosticsParams{}
+	if err := json.Unmarshal(replies[1]["params"], diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	var wrongType *Diagnostic
+	for i, d := range diagnostics.Diagnostics {
+		if d.Code == "KY003" {
+			wrongType = &diagnostics.Diagnostics[i]
+		}
+	}
+	expectedRange := Range{Start: Position{Line: 8, Character: 21}, End: Position{Line: 8, Character: 22}}
+	if wrongType == nil || wrongType.Range != expectedRange || wrongType.Severity != severityError {
+		t.Fatalf("expected the wrong type of imagePullPolicy at %v but got %s", expectedRange, replies[1]["params"])
+	}
+
+	hover := &Hover{}
+	if err := json.Unmarshal(replies[2]["result"], hover); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hover.Contents.Value, "**imagePullPolicy** `string`\n\nImage pull policy.") {
+		t.Fatalf("unexpected hover %s", replies[2]["result"])
+	}
+
+	completion := &CompletionList{}
+	if err := json.Unmarshal(replies[3]["result"], completion); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, item := range completion.Items {
+		if item.Label == "livenessProbe" && item.Detail == "Probe" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected the fields of a container but got %s", replies[3]["result"])
+	}
+
+	if !bytes.Contains(replies[4]["error"], []byte(`"code":-32601`)) {
+		t.Fatalf("expected method not found but got %s", replies[4]["error"])
+	}
+	if string(replies[5]["result"]) != "null" {
+		t.Fatalf("expected a null result for shutdown but got %s", replies[5]["result"])
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	resolver, err := kubernetes.NewResolver("1.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io"), []*kubernetes.Validator{kubernetes.NewValidator(resolver)})
+	in := &bytes.Buffer{}
+	if err := newConn(nil, in).write(map[string]interface{}{"jsonrpc": "2.0", "method": "exit"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Run(in, ioutil.Discard); err == nil {
+		t.Fatal("expected an error for exit without shutdown")
+	}
+}
+
+func TestMultibyteCharacters(t *testing.T) {
+	// é is one UTF-16 code unit but two bytes, 😀 is two UTF-16 code units but four bytes
+	text := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  labels: {émoji: 😀, count: 3}
+`
+	replies := session(t,
+		call(1, "initialize", map[string]interface{}{}),
+		map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "yaml", "version": 1, "text": text},
+		}},
+		call(2, "shutdown", nil),
+		map[string]interface{}{"jsonrpc": "2.0", "method": "exit"},
+	)
+	if len(replies) != 3 {
+		t.Fatalf("expected 2 responses and a notification but got %d", len(replies))
+	}
+	diagnostics := &PublishDiagnosticsParams{}
+	if err := json.Unmarshal(replies[1]["params"], diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	expectedRange := Range{Start: Position{Line: 4, Character: 29}, End: Position{Line: 4, Character: 30}}
+	for _, d := range diagnostics.Diagnostics {
+		if d.Code == "KY003" && d.Range == expectedRange {
+			return
+		}
+	}
+	t.Fatalf("expected the wrong type of count at %v but got %s", expectedRange, replies[1]["params"])
+}
+
+func TestCharacters(t *testing.T) {
+	line := `  name: "😀é" # x`
+	testcases := []struct {
+		column    int
+		character int
+		offset    int
+	}{
+		{column: 0, character: 0, offset: 0},
+		{column: 9, character: 9, offset: 9},
+		{column: 10, character: 11, offset: 13},
+		{column: 11, character: 12, offset: 15},
+		{column: 100, character: 17, offset: len(line)},
+	}
+	for _, tc := range testcases {
+		if c := character(line, tc.column); c != tc.character {
+			t.Errorf("expected column %d to be character %d but got %d", tc.column, tc.character, c)
+		}
+		if tc.column <= 11 {
+			if o := byteOffset(line, tc.character); o != tc.offset {
+				t.Errorf("expected character %d to be byte %d but got %d", tc.character, tc.offset, o)
+			}
+		}
+	}
+}
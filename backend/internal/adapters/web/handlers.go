@@ -1,13 +1,16 @@
 package web
 
 import (
-	"encoding/json"
 	"html/template"
 	"io/ioutil"
 	"net/http"
-	"net/url"
+	"strings"
 
 	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/messages"
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/report"
 )
 
 func (s *Server) main(w http.ResponseWriter, r *http.Request) {
@@ -23,59 +26,139 @@ func (s *Server) validate(w http.ResponseWriter, r *http.Request) {
 	s.logRequest("validate", r)
 
 	if r.Method != "POST" {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", "POST")
+		s.writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
 		return
 	}
 
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		s.log.Infof("error reading body: %v\n", err)
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		s.writeError(w, http.StatusBadRequest, "failed to read the request body")
 		return
 	}
 	defer r.Body.Close()
 
-	// data is posted with plain HTML so we get `data=url+encoded+yaml&key=value...`
-	v, err := url.ParseQuery(string(b))
+	req, err := parseValidateRequest(r.Header.Get("Content-Type"), b, r.URL.Query())
 	if err != nil {
-		s.log.Infof("error parsing value string: %v\n", err)
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		reqErr, ok := err.(*requestError)
+		if !ok {
+			s.log.Infof("error parsing request: %v\n", err)
+			s.writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+		s.writeError(w, reqErr.status, reqErr.message)
 		return
 	}
 
-	// Extract the yaml and load it
-	data := v.Get("data")
-	if len(data) == 0 {
-		// Ignore empty requests
-		return
-	}
-	if err := s.svc.Validate([]byte(data)); err != nil {
-		switch err.(type) {
-		case *kubernetes.RequiredKeyNotFoundError:
-		case *kubernetes.YamlPathError:
-		default:
-			s.log.Infof("error loading body with non user error: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	// severity is the least severe problem to report, everything is reported by default
+	min := problem.SeverityInfo
+	if req.severity != "" {
+		min, err = problem.ParseSeverity(req.severity)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		errs := make(map[string][]error)
-		// TODO: use the incoming versions instead of the hardcoded ons
-		// for _, v := range s.validators {
-		// 	errs[v.Version()] = []error{err}
-		// }
+	}
 
-		out, err := json.Marshal(errs)
+	// reports are asked for by name or with an Accept header, everything else gets the versioned JSON response
+	format := req.format
+	if format == "" {
+		format = acceptedFormat(r.Header.Get("Accept"))
+	}
+	var reporter report.Reporter
+	if format != "" && format != "json" {
+		reporter, err = report.New(format)
 		if err != nil {
-			s.log.Infof("error marshalling errors: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			s.writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+	}
 
-		if _, err := w.Write(out); err != nil {
-			s.log.Infof("error writing response body: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	result, err := s.svc.Validate(req.data, req.versions...)
+	if err != nil {
+		if _, ok := err.(*validation.UnknownVersionError); ok {
+			s.writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		// everything else means the input isn't a stream of YAML documents
+		s.log.Infof("error loading body with non user error: %v\n", err)
+		s.writeError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
+	result.Filter(min)
+
+	if reporter != nil {
+		s.writeReport(w, reporter, newReport(req.file, result))
+		return
+	}
+
+	if req.form {
+		s.writeJSON(w, http.StatusOK, result.Documents)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, &ValidateResponse{
+		APIVersion: ResponseVersion,
+		Valid:      result.Valid(),
+		Versions:   result.Versions,
+		Documents:  result.Documents,
+	})
+}
+
+func (s *Server) explain(w http.ResponseWriter, r *http.Request) {
+	s.logRequest("explain", r)
+
+	if s.explainer == nil {
+		s.writeError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		return
+	}
+	if r.Method != "GET" {
+		w.Header().Set("Allow", "GET")
+		s.writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	q := r.URL.Query()
+	apiVersion, kind := q.Get("apiVersion"), q.Get("kind")
+	if apiVersion == "" || kind == "" {
+		s.writeError(w, http.StatusBadRequest, "apiVersion and kind are required")
+		return
+	}
+	var path []string
+	if p := strings.Trim(q.Get("path"), "."); p != "" {
+		path = strings.Split(p, ".")
+	}
+
+	explanation, err := s.explainer.Explain(q.Get("version"), apiVersion, kind, path)
+	if err != nil {
+		if _, ok := err.(*kubernetes.UnknownVersionError); ok {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// either the kind or one of the keys in the path doesn't exist
+		s.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, &ExplainResponse{
+		APIVersion:  ResponseVersion,
+		Version:     explanation.Version,
+		Path:        explanation.Path,
+		Type:        explanation.Type,
+		Description: explanation.Description,
+		Required:    explanation.Required,
+		Fields:      explanation.Fields,
+	})
+}
+
+func (s *Server) versions(w http.ResponseWriter, r *http.Request) {
+	s.logRequest("versions", r)
+
+	versions := s.svc.Versions()
+	resp := messages.VersionsResponse{
+		Versions: versions,
+	}
+	if len(versions) > 0 {
+		resp.DefaultVersion = versions[0]
+	}
+	s.writeJSON(w, http.StatusOK, resp)
 }
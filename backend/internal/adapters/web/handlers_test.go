@@ -0,0 +1,388 @@
+package web
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/logging"
+)
+
+const pod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx:1.15
+    imagePullPolicy: 3
+`
+
+func TestValidate(t *testing.T) {
+	svc, err := validation.NewService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(svc)
+	s.log = &logging.Log{Writer: ioutil.Discard}
+
+	testcases := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		status      int
+		// check looks at the decoded body of a successful response
+		check func(t *testing.T, body []byte)
+	}{
+		{
+			name:        "yaml",
+			contentType: "application/yaml",
+			target:      "/validate?versions=1.12&severity=error",
+			body:        pod,
+			status:      http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp := &ValidateResponse{}
+				if err := json.Unmarshal(body, resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.APIVersion != ResponseVersion || resp.Valid || len(resp.Documents) != 1 {
+					t.Fatalf("expected one invalid document but got %s", body)
+				}
+				errs := resp.Documents[0].Errors["1.12"]
+				if len(errs) != 1 || errs[0].Path != "spec.containers.0.imagePullPolicy" {
+					t.Fatalf("expected only the wrong type of imagePullPolicy but got %s", body)
+				}
+			},
+		},
+		{
+			name:        "a list of json manifests",
+			contentType: "application/json",
+			target:      "/validate?versions=1.12",
+			body:        `[{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "a"}}, {"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "b"}}]`,
+			status:      http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp := &ValidateResponse{}
+				if err := json.Unmarshal(body, resp); err != nil {
+					t.Fatal(err)
+				}
+				if !resp.Valid || len(resp.Documents) != 2 {
+					t.Fatalf("expected two valid documents but got %s", body)
+				}
+			},
+		},
+		{
+			name:        "envelope",
+			contentType: "application/json; charset=utf-8",
+			target:      "/validate",
+			body:        `{"documents": ["apiVersion: v1\nkind: Namespace\nmetadata: {name: a}", {"apiVersion": "v1", "kind": "Namespace"}], "versions": ["1.11"]}`,
+			status:      http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp := &ValidateResponse{}
+				if err := json.Unmarshal(body, resp); err != nil {
+					t.Fatal(err)
+				}
+				if len(resp.Versions) != 1 || resp.Versions[0] != "1.11" || len(resp.Documents) != 2 {
+					t.Fatalf("expected two documents validated against 1.11 but got %s", body)
+				}
+			},
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			target:      "/validate",
+			body:        "versions=1.12&data=" + strings.Replace(pod, "\n", "%0A", -1),
+			status:      http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				documents := []*validation.DocumentResult{}
+				if err := json.Unmarshal(body, &documents); err != nil {
+					t.Fatalf("expected the original list of documents but got %s", body)
+				}
+			},
+		},
+		{
+			name:        "no documents",
+			contentType: "application/yaml",
+			target:      "/validate",
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "not yaml",
+			contentType: "application/yaml",
+			target:      "/validate",
+			body:        "a: [",
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "unknown version",
+			contentType: "application/yaml",
+			target:      "/validate?versions=1.2",
+			body:        pod,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "unknown severity",
+			contentType: "application/yaml",
+			target:      "/validate?severity=fatal",
+			body:        pod,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "application/xml",
+			target:      "/validate",
+			body:        "<pod/>",
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "not a post",
+			method: "GET",
+			target: "/validate",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = "POST"
+			}
+			r := httptest.NewRequest(method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			w := httptest.NewRecorder()
+			s.svr.Handler.ServeHTTP(w, r)
+
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d but got %d: %s", tc.status, w.Code, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Fatalf("expected a JSON response but got %q", ct)
+			}
+			if tc.status != http.StatusOK {
+				resp := &ErrorResponse{}
+				if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil || resp.Status != tc.status || resp.Error == "" {
+					t.Fatalf("expected an error body but got %s", w.Body)
+				}
+				return
+			}
+			tc.check(t, w.Body.Bytes())
+		})
+	}
+}
+
+func TestValidateReports(t *testing.T) {
+	svc, err := validation.NewService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(svc)
+	s.log = &logging.Log{Writer: ioutil.Discard}
+
+	testcases := []struct {
+		name        string
+		target      string
+		accept      string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{
+			name:        "sarif by name",
+			target:      "/validate?versions=1.12&format=sarif&file=pod.yaml",
+			status:      http.StatusOK,
+			contentType: "application/sarif+json",
+			contains:    `"uri": "pod.yaml"`,
+		},
+		{
+			name:        "junit from the accept header",
+			target:      "/validate?versions=1.12",
+			accept:      "application/junit+xml, application/json;q=0.5",
+			status:      http.StatusOK,
+			contentType: "application/xml; charset=utf-8",
+			contains:    `<failure message="key imagePullPolicy has wrong type integer (should be string)" type="KY003">`,
+		},
+		{
+			name:        "json when the accept header of axios takes it",
+			target:      "/validate?versions=1.12",
+			accept:      "application/json, text/plain, */*",
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			contains:    `"APIVersion":"v1"`,
+		},
+		{
+			name:        "text preferred by q-value",
+			target:      "/validate?versions=1.12",
+			accept:      "application/json;q=0.5, text/plain",
+			status:      http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			contains:    "KY003",
+		},
+		{
+			name:        "q-value of zero is not acceptable",
+			target:      "/validate?versions=1.12",
+			accept:      "text/plain;q=0, */*;q=0.1",
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			contains:    `"APIVersion":"v1"`,
+		},
+		{
+			name:        "github annotations",
+			target:      "/validate?versions=1.12&format=github",
+			status:      http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			contains:    "::error file=manifest.yaml,line=9,col=22",
+		},
+		{
+			name:        "format wins over accept",
+			target:      "/validate?versions=1.12&format=json",
+			accept:      "text/plain",
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			contains:    `"APIVersion":"v1"`,
+		},
+		{
+			name:        "unknown format",
+			target:      "/validate?format=html",
+			status:      http.StatusBadRequest,
+			contentType: "application/json; charset=utf-8",
+			contains:    `unknown format \"html\"`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tc.target, strings.NewReader(pod))
+			r.Header.Set("Content-Type", "application/yaml")
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			s.svr.Handler.ServeHTTP(w, r)
+
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d but got %d: %s", tc.status, w.Code, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tc.contentType {
+				t.Fatalf("expected Content-Type %q but got %q", tc.contentType, ct)
+			}
+			if !strings.Contains(w.Body.String(), tc.contains) {
+				t.Fatalf("expected the body to contain %q but got %s", tc.contains, w.Body)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	svc, err := validation.NewService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validators := make([]*kubernetes.Validator, 0)
+	for _, version := range []string{"1.12", "1.11"} {
+		resolver, err := kubernetes.NewResolver(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		validators = append(validators, kubernetes.NewValidator(resolver))
+	}
+	explainer := kubernetes.NewExplainer(kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io"), validators...)
+	s := NewServer(svc, WithExplainer(explainer))
+	s.log = &logging.Log{Writer: ioutil.Discard}
+
+	testcases := []struct {
+		name   string
+		method string
+		target string
+		status int
+		// check looks at the decoded body of a successful response
+		check func(t *testing.T, resp *ExplainResponse)
+	}{
+		{
+			name:   "field",
+			target: "/explain?version=1.12&apiVersion=apps/v1&kind=Deployment&path=spec.template.spec.containers",
+			status: http.StatusOK,
+			check: func(t *testing.T, resp *ExplainResponse) {
+				if resp.APIVersion != ResponseVersion || resp.Version != "1.12" || resp.Type != "[]Container" || !resp.Required {
+					t.Fatalf("unexpected explanation %+v", resp)
+				}
+				for _, f := range resp.Fields {
+					if f.Name == "name" && f.Type == "string" && f.Required {
+						return
+					}
+				}
+				t.Fatalf("expected the required name of a container but got %+v", resp.Fields)
+			},
+		},
+		{
+			name:   "object",
+			target: "/explain?apiVersion=v1&kind=Pod",
+			status: http.StatusOK,
+			check: func(t *testing.T, resp *ExplainResponse) {
+				if resp.Path != "" || resp.Type != "Pod" || resp.Description == "" || len(resp.Fields) == 0 {
+					t.Fatalf("expected the newest Pod but got %+v", resp)
+				}
+			},
+		},
+		{
+			name:   "unknown key",
+			target: "/explain?version=1.12&apiVersion=v1&kind=Pod&path=spec.contianers",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "unknown version",
+			target: "/explain?version=0.1&apiVersion=v1&kind=Pod",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing kind",
+			target: "/explain?apiVersion=v1",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "post",
+			method: "POST",
+			target: "/explain?apiVersion=v1&kind=Pod",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	t.Run("without an explainer", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewServer(svc).svr.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/explain?apiVersion=v1&kind=Pod", nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d but got %d: %s", http.StatusNotFound, w.Code, w.Body)
+		}
+	})
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = "GET"
+			}
+			w := httptest.NewRecorder()
+			s.svr.Handler.ServeHTTP(w, httptest.NewRequest(method, tc.target, nil))
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d but got %d: %s", tc.status, w.Code, w.Body)
+			}
+			if tc.check == nil {
+				resp := &ErrorResponse{}
+				if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil || resp.APIVersion != ResponseVersion || resp.Error == "" {
+					t.Fatalf("expected a versioned error but got %s", w.Body)
+				}
+				return
+			}
+			resp := &ExplainResponse{}
+			if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, resp)
+		})
+	}
+}
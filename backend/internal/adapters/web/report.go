@@ -0,0 +1,104 @@
+package web
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/report"
+)
+
+// defaultFile names the documents in reports when the request doesn't.
+const defaultFile = "manifest.yaml"
+
+// acceptFormats are the report formats that can be asked for with an Accept header.
+// JSON isn't here, it's the versioned JSON response that is sent by default.
+var acceptFormats = map[string]string{
+	"application/sarif+json": "sarif",
+	"application/junit+xml":  "junit",
+	"application/xml":        "junit",
+	"text/xml":               "junit",
+	"text/plain":             "text",
+}
+
+// acceptedFormat is the format of the most preferred media range in an Accept header, or empty for the default response.
+// Ranges are ranked by q-value. At the same q-value an explicit application/json comes first, then the report formats,
+// then the wildcards that the default response also satisfies. Ranges with a q-value of 0 and unknown types are skipped.
+func acceptedFormat(accept string) string {
+	type candidate struct {
+		format string
+		q      float64
+		rank   int
+	}
+	candidates := make([]candidate, 0)
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			candidates = append(candidates, candidate{q: q, rank: 0})
+		case "*/*", "application/*":
+			candidates = append(candidates, candidate{q: q, rank: 2})
+		default:
+			if format, ok := acceptFormats[mediaType]; ok {
+				candidates = append(candidates, candidate{format: format, q: q, rank: 1})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		return candidates[i].rank < candidates[j].rank
+	})
+	return candidates[0].format
+}
+
+// newReport turns a result into a report about a single file.
+func newReport(file string, result *validation.Result) *report.Report {
+	if file == "" {
+		file = defaultFile
+	}
+	r := &report.Report{Files: []string{file}}
+	for _, d := range result.Documents {
+		for _, version := range result.Versions {
+			for _, detail := range d.Errors[version] {
+				r.Findings = append(r.Findings, &report.Finding{File: file, Document: d.Index, Version: version, Detail: detail})
+			}
+		}
+	}
+	report.Sort(r.Findings)
+	return r
+}
+
+func (s *Server) writeReport(w http.ResponseWriter, reporter report.Reporter, r *report.Report) {
+	out := &bytes.Buffer{}
+	if err := reporter.Write(out, r); err != nil {
+		s.log.Infof("error writing report: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", reporter.ContentType())
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(out.Bytes()); err != nil {
+		s.log.Infof("error writing response body: %v\n", err)
+	}
+}
@@ -0,0 +1,205 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// validateRequest is everything /validate needs, whatever the content type of the request was.
+type validateRequest struct {
+	// data is a stream of YAML documents.
+	data     []byte
+	versions []string
+	severity string
+	// format is the report format to reply with, the versioned JSON response when it's empty.
+	format string
+	// file names the documents in reports that point at files, such as SARIF.
+	file string
+	// form is true for the original form encoded requests, they get the original response.
+	form bool
+}
+
+// envelope is the JSON body that wraps documents with the options to validate them with.
+// Documents is a YAML stream as a string, or a list where each item is a YAML document as a string or a manifest as an object.
+type envelope struct {
+	Documents json.RawMessage
+	Versions  []string
+	Severity  string
+	Format    string
+	File      string
+}
+
+// requestError is a request that can't be validated and the status code to reply with.
+type requestError struct {
+	status  int
+	message string
+}
+
+func (r *requestError) Error() string {
+	return r.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &requestError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// parseValidateRequest reads the body of a request to /validate based on its content type.
+//
+//	application/x-www-form-urlencoded  data=<yaml>&versions=1.11,1.12&severity=warning&format=sarif, also sent as text/plain by the frontend
+//	application/yaml                   a YAML stream
+//	application/json                   a manifest, a list of manifests or an envelope
+//
+// Versions, severity, format and file can always be set in the query string as well.
+func parseValidateRequest(contentType string, body []byte, query url.Values) (*validateRequest, error) {
+	mediaType := "text/plain"
+	if contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, badRequest("invalid Content-Type %q: %v", contentType, err)
+		}
+	}
+
+	req := &validateRequest{
+		versions: splitVersions(query["versions"]),
+		severity: query.Get("severity"),
+		format:   query.Get("format"),
+		file:     query.Get("file"),
+	}
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "text/plain":
+		// data is posted with plain HTML so we get `data=url+encoded+yaml&key=value...`
+		v, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, badRequest("invalid form: %v", err)
+		}
+		req.form = true
+		req.data = []byte(v.Get("data"))
+		req.versions = append(splitVersions(v["versions"]), req.versions...)
+		if severity := v.Get("severity"); severity != "" {
+			req.severity = severity
+		}
+		if format := v.Get("format"); format != "" {
+			req.format = format
+		}
+		if file := v.Get("file"); file != "" {
+			req.file = file
+		}
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		req.data = body
+	case "application/json":
+		if err := req.parseJSON(body); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &requestError{
+			status:  http.StatusUnsupportedMediaType,
+			message: fmt.Sprintf("unsupported Content-Type %q (should be application/yaml, application/json or application/x-www-form-urlencoded)", mediaType),
+		}
+	}
+	if len(bytes.TrimSpace(req.data)) == 0 {
+		return nil, badRequest("no documents to validate")
+	}
+	return req, nil
+}
+
+// parseJSON tells an envelope from manifests by its documents key. JSON is YAML so manifests are validated as they are.
+func (r *validateRequest) parseJSON(body []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		// not an object, maybe a list of manifests
+		var list []json.RawMessage
+		if err := json.Unmarshal(body, &list); err != nil {
+			return badRequest("invalid JSON: %v", err)
+		}
+		data, err := joinDocuments(list)
+		if err != nil {
+			return err
+		}
+		r.data = data
+		return nil
+	}
+	if !isEnvelope(object) {
+		r.data = body
+		return nil
+	}
+
+	env := &envelope{}
+	if err := json.Unmarshal(body, env); err != nil {
+		return badRequest("invalid envelope: %v", err)
+	}
+	r.versions = append(splitVersions(env.Versions), r.versions...)
+	if env.Severity != "" {
+		r.severity = env.Severity
+	}
+	if env.Format != "" {
+		r.format = env.Format
+	}
+	if env.File != "" {
+		r.file = env.File
+	}
+	var stream string
+	if err := json.Unmarshal(env.Documents, &stream); err == nil {
+		r.data = []byte(stream)
+		return nil
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(env.Documents, &list); err != nil {
+		return badRequest("documents should be a string or a list but got %s", env.Documents)
+	}
+	data, err := joinDocuments(list)
+	if err != nil {
+		return err
+	}
+	r.data = data
+	return nil
+}
+
+// isEnvelope is true for an object with documents, field names are matched like encoding/json does.
+func isEnvelope(object map[string]json.RawMessage) bool {
+	for key := range object {
+		if strings.EqualFold(key, "documents") {
+			return true
+		}
+	}
+	return false
+}
+
+// joinDocuments turns a list of YAML strings and JSON manifests into a YAML stream.
+func joinDocuments(list []json.RawMessage) ([]byte, error) {
+	documents := make([][]byte, len(list))
+	for i, raw := range list {
+		raw = bytes.TrimSpace(raw)
+		switch {
+		case bytes.HasPrefix(raw, []byte("{")):
+			documents[i] = raw
+		case bytes.HasPrefix(raw, []byte(`"`)):
+			var document string
+			if err := json.Unmarshal(raw, &document); err != nil {
+				return nil, badRequest("invalid document %d: %v", i, err)
+			}
+			documents[i] = []byte(document)
+		default:
+			return nil, badRequest("document %d should be a string or an object but got %s", i, raw)
+		}
+	}
+	return bytes.Join(documents, []byte("\n---\n")), nil
+}
+
+// splitVersions accepts versions as `versions=1.11&versions=1.12` or `versions=1.11,1.12`.
+func splitVersions(values []string) []string {
+	versions := make([]string, 0)
+	for _, vs := range values {
+		for _, version := range strings.Split(vs, ",") {
+			if version = strings.TrimSpace(version); version != "" {
+				versions = append(versions, version)
+			}
+		}
+	}
+	return versions
+}
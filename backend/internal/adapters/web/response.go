@@ -0,0 +1,65 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
+)
+
+// ResponseVersion is the version of the JSON responses of /validate and /explain. It changes whenever their shape does.
+const ResponseVersion = "v1"
+
+// ValidateResponse is the response to a YAML or JSON request to /validate.
+// Form encoded requests get the list of documents on its own, that's what the frontend reads.
+type ValidateResponse struct {
+	APIVersion string
+	// Valid is true when no document has any errors, warnings and info don't count.
+	Valid bool
+	// Versions are the kubernetes versions the documents were validated against, from newest to oldest.
+	Versions  []string
+	Documents []*validation.DocumentResult
+}
+
+// ExplainResponse is the response to /explain, the documentation of a field and of the fields under it.
+type ExplainResponse struct {
+	APIVersion string
+	// Version is the kubernetes version the documentation comes from.
+	Version string
+	// Path is the dotted path to the field, it's empty for the object itself.
+	Path        string
+	Type        string
+	Description string
+	Required    bool
+	Fields      []*kubernetes.FieldSummary
+}
+
+// ErrorResponse is the body of every response to /validate and /explain that isn't a 200.
+type ErrorResponse struct {
+	APIVersion string
+	Status     int
+	Error      string
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		s.log.Infof("error marshalling response: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := w.Write(out); err != nil {
+		s.log.Infof("error writing response body: %v\n", err)
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	s.writeJSON(w, status, &ErrorResponse{
+		APIVersion: ResponseVersion,
+		Status:     status,
+		Error:      message,
+	})
+}
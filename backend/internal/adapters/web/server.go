@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/service/validation"
 	"github.com/chuckha/kubeyaml.com/backend/internal/shared/logging"
 )
 
@@ -13,7 +15,12 @@ const (
 )
 
 type service interface {
-	Validate([]byte) error
+	Validate(input []byte, versions ...string) (*validation.Result, error)
+	Versions() []string
+}
+
+type explainer interface {
+	Explain(version, apiVersion, kind string, path []string) (*kubernetes.Explanation, error)
 }
 
 type logger interface {
@@ -24,7 +31,9 @@ type Server struct {
 	svr *http.Server
 	dev bool
 	svc service
-	log logger
+	// explainer documents fields for /explain, which is not found without one.
+	explainer explainer
+	log       logger
 }
 
 type ServerOption func(s *Server)
@@ -41,13 +50,23 @@ func WithDevMode(dev bool) ServerOption {
 	}
 }
 
+// WithExplainer serves the documentation of fields on /explain.
+func WithExplainer(e explainer) ServerOption {
+	return func(s *Server) {
+		s.explainer = e
+	}
+}
+
 func NewServer(svc service, opts ...ServerOption) *Server {
 	s := &Server{
 		dev: defaultDevMode,
+		svc: svc,
 		log: &logging.Log{Writer: os.Stdout},
 	}
 	mux := http.NewServeMux()
 	mux.HandleFunc("/validate", s.corsForDev(s.validate))
+	mux.HandleFunc("/versions", s.corsForDev(s.versions))
+	mux.HandleFunc("/explain", s.corsForDev(s.explain))
 	mux.HandleFunc("/favicon.ico", s.corsForDev(s.favicon))
 	mux.Handle("/static/", http.StripPrefix("/static", http.FileServer(http.Dir("static"))))
 	mux.HandleFunc("/", s.main)
@@ -0,0 +1,135 @@
+package kubernetes_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
+)
+
+func TestAdvice(t *testing.T) {
+	resources := map[interface{}]interface{}{
+		"limits":   map[interface{}]interface{}{"memory": "128Mi"},
+		"requests": map[interface{}]interface{}{"cpu": "100m"},
+	}
+	deployment := func(containers ...interface{}) map[interface{}]interface{} {
+		return map[interface{}]interface{}{
+			"spec": map[interface{}]interface{}{
+				"template": map[interface{}]interface{}{
+					"spec": map[interface{}]interface{}{
+						"containers": containers,
+					},
+				},
+			},
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		kind     string
+		incoming map[interface{}]interface{}
+		// expected maps a path to the codes found there
+		expected map[string][]problem.Code
+	}{
+		{
+			name:     "pinned image with resources",
+			kind:     "Deployment",
+			incoming: deployment(map[interface{}]interface{}{"name": "web", "image": "nginx:1.15", "resources": resources}),
+			expected: map[string][]problem.Code{},
+		},
+		{
+			name: "latest and untagged images without resources",
+			kind: "Deployment",
+			incoming: deployment(
+				map[interface{}]interface{}{"name": "web", "image": "nginx:latest"},
+				map[interface{}]interface{}{"name": "sidecar", "image": "localhost:5000/proxy", "resources": map[interface{}]interface{}{"limits": map[interface{}]interface{}{}}},
+			),
+			expected: map[string][]problem.Code{
+				"spec.template.spec.containers.0.image": {problem.CodeLatestImageTag},
+				"spec.template.spec.containers.0":       {problem.CodeMissingResourceLimits, problem.CodeMissingResourceRequests},
+				"spec.template.spec.containers.1.image": {problem.CodeLatestImageTag},
+				"spec.template.spec.containers.1":       {problem.CodeMissingResourceLimits, problem.CodeMissingResourceRequests},
+			},
+		},
+		{
+			name: "digests and registry ports are not tags",
+			kind: "Deployment",
+			incoming: deployment(
+				map[interface{}]interface{}{"name": "web", "image": "nginx@sha256:abc", "resources": resources},
+				map[interface{}]interface{}{"name": "proxy", "image": "localhost:5000/proxy:1.0", "resources": resources},
+			),
+			expected: map[string][]problem.Code{},
+		},
+		{
+			name: "init containers in cronjobs",
+			kind: "CronJob",
+			incoming: map[interface{}]interface{}{
+				"spec": map[interface{}]interface{}{
+					"jobTemplate": map[interface{}]interface{}{
+						"spec": map[interface{}]interface{}{
+							"template": map[interface{}]interface{}{
+								"spec": map[interface{}]interface{}{
+									"initContainers": []interface{}{map[interface{}]interface{}{"name": "init", "image": "busybox", "resources": resources}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: map[string][]problem.Code{
+				"spec.jobTemplate.spec.template.spec.initContainers.0.image": {problem.CodeLatestImageTag},
+			},
+		},
+		{
+			name: "kinds without containers",
+			kind: "Service",
+			incoming: map[interface{}]interface{}{
+				"spec": map[interface{}]interface{}{"containers": []interface{}{map[interface{}]interface{}{"image": "nginx"}}},
+			},
+			expected: map[string][]problem.Code{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := map[string][]problem.Code{}
+			for _, err := range kubernetes.Advise(tc.kind, tc.incoming) {
+				detail := err.(kubernetes.Problem).Detail()
+				if detail.Severity == problem.SeverityError {
+					t.Fatalf("advice should never be an error: %v", err)
+				}
+				actual[detail.Path] = append(actual[detail.Path], detail.Code)
+			}
+			for _, codes := range actual {
+				sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFilterSeverity(t *testing.T) {
+	errs := []error{
+		kubernetes.NewUnknownKeyError("banana"),
+		kubernetes.NewMissingResourcesError("web", "limits"),
+		kubernetes.NewMissingResourcesError("web", "requests"),
+	}
+	testcases := []struct {
+		min      problem.Severity
+		expected int
+	}{
+		{min: problem.SeverityError, expected: 1},
+		{min: problem.SeverityWarning, expected: 2},
+		{min: problem.SeverityInfo, expected: 3},
+	}
+	for _, tc := range testcases {
+		t.Run(string(tc.min), func(t *testing.T) {
+			if actual := kubernetes.FilterSeverity(tc.min, errs...); len(actual) != tc.expected {
+				t.Fatalf("expected %d errors at %s or worse but got %v", tc.expected, tc.min, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/deprecation"
+)
+
+// VersionValidator validates documents against the schemas of a single kubernetes version, like a Validator does.
+type VersionValidator interface {
+	Resolve(string) (*Schema, error)
+	Validate(map[interface{}]interface{}, *Schema) []error
+	Version() string
+}
+
+// KeyFinder finds the schema key of an apiVersion and kind, like an APIKeyer does.
+type KeyFinder interface {
+	APIKey(string, string) string
+}
+
+// Problems are everything found in a loaded document. Every problem that has a path knows where it is in the document.
+type Problems struct {
+	// Document are the same in every version: a document that can't be loaded, duplicate keys and advice.
+	Document []error
+	// Versions are found with the schemas of each version and are keyed by the version.
+	Versions map[string][]error
+}
+
+// Check validates a loaded document against every version and checks its apiVersion hasn't been deprecated or removed.
+// An apiVersion that was removed has no schema, so the removal is reported instead of the missing schema.
+func Check(input *Input, finder KeyFinder, deprecations *deprecation.Index, validators ...VersionValidator) *Problems {
+	problems := &Problems{Versions: make(map[string][]error)}
+	if input.Err != nil {
+		problems.Document = []error{input.Err}
+		return problems
+	}
+	advice := Advise(input.Kind, input.Data)
+	Locate(input.Positions, advice...)
+	problems.Document = append(input.Errors[:len(input.Errors):len(input.Errors)], advice...)
+
+	for _, v := range validators {
+		lifecycle := CheckAPIVersion(deprecations, v.Version(), input.APIVersion, input.Kind)
+		schema, err := v.Resolve(finder.APIKey(input.APIVersion, input.Kind))
+		if err != nil {
+			if lifecycle != nil {
+				err = lifecycle
+			}
+			// the schema comes from the apiVersion and kind, point at the apiVersion
+			err = NewYamlPathError([]string{"apiVersion"}, input.APIVersion, err)
+			Locate(input.Positions, err)
+			problems.Versions[v.Version()] = []error{err}
+			continue
+		}
+		errs := v.Validate(input.Data, schema)
+		if lifecycle != nil {
+			errs = append(errs, NewYamlPathError([]string{"apiVersion"}, input.APIVersion, lifecycle))
+		}
+		Locate(input.Positions, errs...)
+		problems.Versions[v.Version()] = errs
+	}
+	return problems
+}
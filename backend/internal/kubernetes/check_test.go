@@ -0,0 +1,86 @@
+package kubernetes_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
+)
+
+func TestCheck(t *testing.T) {
+	resolver, err := kubernetes.NewResolver("1.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := kubernetes.NewValidator(resolver)
+	finder := kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io")
+
+	testcases := []struct {
+		name string
+		yaml string
+		// document and version are the codes found whatever the version and in 1.12
+		document []problem.Code
+		version  []problem.Code
+		// line is where the first problem found in 1.12 is
+		line int
+	}{
+		{
+			name:     "valid",
+			yaml:     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n",
+			document: []problem.Code{},
+			version:  []problem.Code{},
+		},
+		{
+			name:     "duplicate keys, advice and schema errors",
+			yaml:     "apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\n  name: app\nspec:\n  containers:\n  - name: web\n    image: nginx:1.15\n    imagePullPolicy: 3\n",
+			document: []problem.Code{problem.CodeDuplicateKey, problem.CodeMissingResourceLimits, problem.CodeMissingResourceRequests},
+			version:  []problem.Code{problem.CodeWrongType},
+			line:     10,
+		},
+		{
+			name:     "unknown schemas point at the apiVersion",
+			yaml:     "apiVersion: example.com/v1\nkind: Widget\n",
+			document: []problem.Code{},
+			version:  []problem.Code{problem.CodeUnknownSchema},
+			line:     1,
+		},
+		{
+			name:     "documents that can't be loaded aren't validated",
+			yaml:     "kind: Pod\n",
+			document: []problem.Code{problem.CodeRequiredKeyNotFound},
+			version:  []problem.Code{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			inputs, err := kubernetes.NewLoader().Load(strings.NewReader(tc.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			problems := kubernetes.Check(inputs[0], finder, nil, validator)
+			if codes := codesOf(problems.Document); !reflect.DeepEqual(codes, tc.document) {
+				t.Fatalf("expected %v whatever the version but got %v", tc.document, problems.Document)
+			}
+			errs := problems.Versions["1.12"]
+			if codes := codesOf(errs); !reflect.DeepEqual(codes, tc.version) {
+				t.Fatalf("expected %v in 1.12 but got %v", tc.version, errs)
+			}
+			if len(errs) == 0 {
+				return
+			}
+			if p := kubernetes.DetailOf(errs[0]).Position; p == nil || p.Line != tc.line {
+				t.Fatalf("expected the first problem on line %d but got %+v", tc.line, p)
+			}
+		})
+	}
+}
+
+func codesOf(errs []error) []problem.Code {
+	codes := make([]problem.Code, 0, len(errs))
+	for _, err := range errs {
+		codes = append(codes, kubernetes.DetailOf(err).Code)
+	}
+	return codes
+}
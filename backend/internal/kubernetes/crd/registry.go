@@ -0,0 +1,314 @@
+package crd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	objectMetaRef  = "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+	intOrStringRef = "#/definitions/io.k8s.apimachinery.pkg.util.intstr.IntOrString"
+)
+
+// CustomResource is a single served version of a custom resource definition.
+type CustomResource struct {
+	Group   string
+	Version string
+	Kind    string
+	// Key is the key of the top level schema in the registry's definitions.
+	Key string
+}
+
+// APIVersion is the group/version of the custom resource as it appears in a manifest.
+func (c *CustomResource) APIVersion() string {
+	return c.Group + "/" + c.Version
+}
+
+// Registry converts the openAPIV3Schema of custom resource definitions into swagger 2.0 definitions
+// so they can be unmarshalled into the same schema types as the kubernetes swagger files.
+type Registry struct {
+	resources   map[string]*CustomResource
+	definitions map[string]json.RawMessage
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		resources:   make(map[string]*CustomResource),
+		definitions: make(map[string]json.RawMessage),
+	}
+}
+
+// LoadPath loads every custom resource definition in a file, or in every .yaml, .yml and .json file in a directory.
+func (r *Registry) LoadPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !info.IsDir() {
+		return r.loadFile(path)
+	}
+	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+			return r.loadFile(p)
+		}
+		return nil
+	})
+}
+
+func (r *Registry) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	return errors.Wrapf(r.Load(f), "filename: %s", path)
+}
+
+// Load reads a stream of YAML documents and registers every CustomResourceDefinition in it.
+// Documents that are not custom resource definitions are ignored.
+func (r *Registry) Load(reader io.Reader) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		doc := map[string]interface{}{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Wrap(err, "failed to unmarshal yaml")
+		}
+		if doc["kind"] != "CustomResourceDefinition" {
+			continue
+		}
+		if err := r.add(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// Resources returns every registered custom resource sorted by api version and kind.
+func (r *Registry) Resources() []*CustomResource {
+	out := make([]*CustomResource, 0, len(r.resources))
+	for _, cr := range r.resources {
+		out = append(out, cr)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].APIVersion() != out[j].APIVersion() {
+			return out[i].APIVersion() < out[j].APIVersion()
+		}
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
+
+// Lookup finds the custom resource for an apiVersion and kind.
+func (r *Registry) Lookup(apiVersion, kind string) (*CustomResource, bool) {
+	cr, ok := r.resources[apiVersion+"/"+kind]
+	return cr, ok
+}
+
+// Definitions returns every schema of every custom resource as swagger 2.0 definitions.
+// References between them use the `#/definitions/` prefix like the kubernetes swagger files do.
+func (r *Registry) Definitions() map[string]json.RawMessage {
+	return r.definitions
+}
+
+func (r *Registry) add(doc map[string]interface{}) error {
+	spec := object(doc["spec"])
+	group := str(spec["group"])
+	kind := str(object(spec["names"])["kind"])
+	if group == "" || kind == "" {
+		return errors.Errorf("custom resource definition %q needs spec.group and spec.names.kind", str(object(doc["metadata"])["name"]))
+	}
+
+	// apiextensions.k8s.io/v1beta1 may have one schema for every version
+	shared := object(object(spec["validation"])["openAPIV3Schema"])
+	versions, _ := spec["versions"].([]interface{})
+	if len(versions) == 0 && str(spec["version"]) != "" {
+		versions = []interface{}{map[string]interface{}{"name": spec["version"]}}
+	}
+	for _, v := range versions {
+		version := object(v)
+		name := str(version["name"])
+		if name == "" {
+			continue
+		}
+		if served, ok := version["served"].(bool); ok && !served {
+			continue
+		}
+		schema := object(object(version["schema"])["openAPIV3Schema"])
+		if schema == nil {
+			schema = shared
+		}
+		cr := &CustomResource{
+			Group:   group,
+			Version: name,
+			Kind:    kind,
+			Key:     definitionKey(group, name, kind),
+		}
+		if err := r.addSchema(cr, schema); err != nil {
+			return err
+		}
+		r.resources[cr.APIVersion()+"/"+kind] = cr
+	}
+	return nil
+}
+
+// addSchema converts the top level schema of a custom resource.
+func (r *Registry) addSchema(cr *CustomResource, schema map[string]interface{}) error {
+	if schema == nil {
+		// no schema means anything goes
+		schema = map[string]interface{}{"type": "object", "x-kubernetes-preserve-unknown-fields": true}
+	}
+	c := &converter{definitions: map[string]interface{}{}}
+	root := c.definition(cr.Key, schema)
+	properties, _ := root["properties"].(map[string]interface{})
+	if properties != nil {
+		// metadata is always ObjectMeta, whatever the schema says
+		properties["metadata"] = map[string]interface{}{"$ref": objectMetaRef}
+		delete(properties, "apiVersion")
+		delete(properties, "kind")
+	}
+	// apiVersion and kind are taken out of documents before they are validated
+	if required, ok := root["required"].([]interface{}); ok {
+		kept := make([]interface{}, 0, len(required))
+		for _, k := range required {
+			if k != "apiVersion" && k != "kind" {
+				kept = append(kept, k)
+			}
+		}
+		root["required"] = kept
+	}
+	root["x-kubernetes-group-version-kind"] = []interface{}{
+		map[string]interface{}{"group": cr.Group, "version": cr.Version, "kind": cr.Kind},
+	}
+
+	for key, def := range c.definitions {
+		raw, err := json.Marshal(def)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the schema of %s", key)
+		}
+		r.definitions[key] = raw
+	}
+	return nil
+}
+
+// definitionKey follows the kubernetes naming of custom resources in swagger files, a reversed group then the version and kind.
+func definitionKey(group, version, kind string) string {
+	parts := strings.Split(group, ".")
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(append(parts, version, kind), ".")
+}
+
+// converter turns nested openAPIV3Schema objects into flat swagger definitions that reference each other.
+type converter struct {
+	definitions map[string]interface{}
+}
+
+// definition converts an object schema with properties into a definition stored under key.
+func (c *converter) definition(key string, schema map[string]interface{}) map[string]interface{} {
+	def := map[string]interface{}{}
+	if d := str(schema["description"]); d != "" {
+		def["description"] = d
+	}
+	if required, ok := schema["required"].([]interface{}); ok {
+		def["required"] = required
+	}
+	if preserve, _ := schema["x-kubernetes-preserve-unknown-fields"].(bool); preserve {
+		def["x-kubernetes-preserve-unknown-fields"] = true
+	}
+	properties := object(schema["properties"])
+	if len(properties) == 0 {
+		// an object without properties holds anything
+		def["type"] = "object"
+		c.definitions[key] = def
+		return def
+	}
+	converted := make(map[string]interface{}, len(properties))
+	for name, p := range properties {
+		converted[name] = c.property(key+"."+name, object(p))
+	}
+	def["properties"] = converted
+	c.definitions[key] = def
+	return def
+}
+
+// property converts the schema of a single field. Objects with properties become their own definition at key.
+func (c *converter) property(key string, schema map[string]interface{}) map[string]interface{} {
+	prop := map[string]interface{}{}
+	if d := str(schema["description"]); d != "" {
+		prop["description"] = d
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		prop["nullable"] = true
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		prop["enum"] = enum
+	}
+	if b, _ := schema["x-kubernetes-int-or-string"].(bool); b {
+		prop["$ref"] = intOrStringRef
+		return prop
+	}
+
+	switch t := str(schema["type"]); t {
+	case "array":
+		prop["type"] = "array"
+		prop["items"] = c.items(key, object(schema["items"]))
+	case "object", "":
+		if len(object(schema["properties"])) > 0 {
+			c.definition(key, schema)
+			prop["$ref"] = "#/definitions/" + key
+			return prop
+		}
+		prop["type"] = "object"
+		if ap := object(schema["additionalProperties"]); ap != nil {
+			prop["additionalProperties"] = c.property(key+".additionalProperties", ap)
+		}
+	default:
+		prop["type"] = t
+		if f := str(schema["format"]); f != "" {
+			prop["format"] = f
+		}
+	}
+	return prop
+}
+
+// items converts the schema of the items of an array.
+func (c *converter) items(key string, schema map[string]interface{}) map[string]interface{} {
+	if schema == nil {
+		return map[string]interface{}{"type": "object"}
+	}
+	return c.property(key+".items", schema)
+}
+
+func object(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,200 @@
+package crd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+)
+
+const crontab = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-crd
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: CronTab
+    plural: crontabs
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        required: [apiVersion, kind, spec]
+        properties:
+          spec:
+            type: object
+            required: [cronSpec]
+            properties:
+              cronSpec:
+                type: string
+              replicas:
+                type: integer
+              port:
+                x-kubernetes-int-or-string: true
+              tags:
+                type: array
+                items:
+                  type: string
+              extra:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+              note:
+                type: string
+                nullable: true
+              schedule:
+                type: string
+                enum: [hourly, daily]
+              options:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+                properties:
+                  retries:
+                    type: integer
+  - name: v1alpha1
+    served: false
+    storage: false
+`
+
+// resolver finds custom resources first and falls back to a fake swagger file.
+type resolver struct {
+	custom *kubernetes.CustomResources
+}
+
+func (r *resolver) Resolve(schemaKey string) (*kubernetes.Schema, error) {
+	schemaKey = strings.TrimPrefix(schemaKey, "#/definitions/")
+	if schema, ok := r.custom.Resolve(schemaKey); ok {
+		return schema, nil
+	}
+	if schemaKey == "io.k8s.apimachinery.pkg.util.intstr.IntOrString" {
+		return &kubernetes.Schema{Type: "string", Format: "int-or-string"}, nil
+	}
+	return &kubernetes.Schema{Type: "object"}, nil
+}
+func (r *resolver) Version() string {
+	return "1.12"
+}
+
+func TestRegistry(t *testing.T) {
+	registry := crd.NewRegistry()
+	if err := registry.Load(strings.NewReader(crontab)); err != nil {
+		t.Fatal(err)
+	}
+
+	resources := registry.Resources()
+	if len(resources) != 1 {
+		t.Fatalf("expected 1 served custom resource but got %d", len(resources))
+	}
+	cr, ok := registry.Lookup("stable.example.com/v1", "CronTab")
+	if !ok {
+		t.Fatal("expected to find stable.example.com/v1 CronTab")
+	}
+	if cr.Key != "com.example.stable.v1.CronTab" {
+		t.Fatalf("unexpected key %q", cr.Key)
+	}
+	if _, ok := registry.Lookup("stable.example.com/v1alpha1", "CronTab"); ok {
+		t.Fatal("expected a version that is not served to be skipped")
+	}
+
+	custom, err := kubernetes.NewCustomResources(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := kubernetes.NewValidator(&resolver{custom: custom})
+	schema, err := validator.Resolve(cr.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		incoming map[interface{}]interface{}
+		errors   int
+	}{
+		{
+			name: "valid custom resource",
+			incoming: map[interface{}]interface{}{
+				"metadata": map[interface{}]interface{}{"name": "my-crontab"},
+				"spec": map[interface{}]interface{}{
+					"cronSpec": "* * * * */5",
+					"replicas": 3,
+					"port":     "http",
+					"tags":     []interface{}{"a", "b"},
+					"extra":    map[interface{}]interface{}{"anything": []interface{}{1, 2}},
+					"note":     nil,
+					"schedule": "daily",
+					"options":  map[interface{}]interface{}{"retries": 3, "backoff": "10s"},
+				},
+			},
+		},
+		{
+			name: "missing required field and unknown key",
+			incoming: map[interface{}]interface{}{
+				"spec": map[interface{}]interface{}{
+					"replicas": 3,
+					"image":    "nginx",
+				},
+			},
+			errors: 2,
+		},
+		{
+			name: "value not in the enum",
+			incoming: map[interface{}]interface{}{
+				"spec": map[interface{}]interface{}{
+					"cronSpec": "* * * * */5",
+					"schedule": "weekly",
+				},
+			},
+			errors: 1,
+		},
+		{
+			name: "known keys are checked when unknown keys are kept",
+			incoming: map[interface{}]interface{}{
+				"spec": map[interface{}]interface{}{
+					"cronSpec": "* * * * */5",
+					"options":  map[interface{}]interface{}{"retries": "three", "backoff": "10s"},
+				},
+			},
+			errors: 1,
+		},
+		{
+			name: "null without nullable",
+			incoming: map[interface{}]interface{}{
+				"spec": map[interface{}]interface{}{
+					"cronSpec": "* * * * */5",
+					"replicas": nil,
+				},
+			},
+			errors: 1,
+		},
+		{
+			name: "wrong types",
+			incoming: map[interface{}]interface{}{
+				"spec": map[interface{}]interface{}{
+					"cronSpec": "* * * * */5",
+					"replicas": "three",
+					"tags":     []interface{}{1},
+				},
+			},
+			errors: 2,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validator.Validate(tc.incoming, schema)
+			if len(errs) != tc.errors {
+				t.Fatalf("expected %d errors but got %d: %v", tc.errors, len(errs), errs)
+			}
+		})
+	}
+}
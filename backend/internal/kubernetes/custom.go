@@ -0,0 +1,46 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+)
+
+// CustomResources are the schemas of custom resource definitions.
+// They are resolved alongside the schemas in the swagger files.
+type CustomResources struct {
+	registry    *crd.Registry
+	definitions map[string]*Schema
+}
+
+// NewCustomResources unmarshals every schema in the registry.
+func NewCustomResources(registry *crd.Registry) (*CustomResources, error) {
+	definitions := make(map[string]*Schema)
+	for key, raw := range registry.Definitions() {
+		schema := &Schema{}
+		if err := json.Unmarshal(raw, schema); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal custom resource schema %s: %v", key, err)
+		}
+		definitions[key] = schema
+	}
+	return &CustomResources{
+		registry:    registry,
+		definitions: definitions,
+	}, nil
+}
+
+// APIKey returns the schema key of a registered custom resource.
+func (c *CustomResources) APIKey(apiVersion, kind string) (string, bool) {
+	cr, ok := c.registry.Lookup(apiVersion, kind)
+	if !ok {
+		return "", false
+	}
+	return cr.Key, true
+}
+
+// Resolve returns the schema of a custom resource.
+func (c *CustomResources) Resolve(schemaKey string) (*Schema, bool) {
+	schema, ok := c.definitions[schemaKey]
+	return schema, ok
+}
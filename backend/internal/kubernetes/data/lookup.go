@@ -1,63 +1,75 @@
 package data
 
 import (
-	"fmt"
-	"io/ioutil"
+	"compress/gzip"
+	"embed"
+	"io"
+	"io/fs"
 	"strings"
 
+	"github.com/chuckha/kubeyaml.com/backend/internal"
 	"github.com/pkg/errors"
 )
 
-func AllStaticFiles() (map[string][]byte, error) {
-	out := make(map[string][]byte)
-	infos, err := ioutil.ReadDir(".")
+const (
+	filePrefix = "swagger-"
+	fileSuffix = ".json.gz"
+)
+
+// swaggers holds the gzipped swagger file for every kubernetes version kubeyaml supports.
+// Files are named swagger-<major>.<minor>.json.gz and are written by scripts/update-schemas.go.
+//
+//go:embed swagger-*.json.gz
+var swaggers embed.FS
+
+// Versions returns every kubernetes version that has a swagger file, from newest to oldest.
+func Versions() ([]string, error) {
+	files, err := fs.Glob(swaggers, filePrefix+"*"+fileSuffix)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	for _, fi := range infos {
-		if strings.HasSuffix(fi.Name(), ".json") {
-			data, err := ioutil.ReadFile(fi.Name())
-			if err != nil {
-				return nil, errors.Wrapf(err, "filename: %s", fi.Name())
-			}
-			version := strings.TrimPrefix(fi.Name(), "swagger-")
-			version = strings.TrimSuffix(version, ".json")
-			out[version] = data
-		}
+	versions := make([]string, len(files))
+	for i, file := range files {
+		versions[i] = strings.TrimSuffix(strings.TrimPrefix(file, filePrefix), fileSuffix)
 	}
-	return out, nil
+	return internal.SortVersions(versions...)
+}
+
+// Has returns true if there is a swagger file for the kubernetes version.
+func Has(version string) bool {
+	_, err := fs.Stat(swaggers, filename(version))
+	return err == nil
+}
+
+// Open returns the uncompressed swagger file for the kubernetes version.
+// The caller must close the returned reader.
+func Open(version string) (io.ReadCloser, error) {
+	f, err := swaggers.Open(filename(version))
+	if err != nil {
+		return nil, errors.Errorf("no swagger file for kubernetes version %q", version)
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "filename: %s", filename(version))
+	}
+	return &swaggerFile{Reader: gz, file: f}, nil
+}
+
+func filename(version string) string {
+	return filePrefix + version + fileSuffix
+}
+
+// swaggerFile closes both the gzip reader and the underlying file.
+type swaggerFile struct {
+	*gzip.Reader
+	file fs.File
 }
 
-type StaticFiles struct{}
-
-// Swagger is a fairly meh function. It's poorly named and tied to the update-schemas file.
-func (s *StaticFiles) Swagger(version string) []byte {
-	switch version {
-	case "1.8":
-		return s.OneEight()
-	case "1.9":
-		return s.OneNine()
-	case "1.10":
-		return s.OneTen()
-	case "1.11":
-		return s.OneEleven()
-	case "1.12":
-		return s.OneTwelve()
-	case "1.13":
-		return s.OneThirteen()
-	case "1.14":
-		return s.OneFourteen()
-	case "1.15":
-		return s.OneFifteen()
-	case "1.16":
-		return s.OneSixteen()
-	case "1.17":
-		return s.OneSeventeen()
-	case "1.18":
-		return s.OneEighteen()
-	case "1.19":
-		return s.OneNineteen()
-	default:
-		panic(fmt.Sprintf("unknown version %v", version))
+func (s *swaggerFile) Close() error {
+	if err := s.Reader.Close(); err != nil {
+		s.file.Close()
+		return err
 	}
+	return s.file.Close()
 }
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVersions(t *testing.T) {
+	versions, err := Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) == 0 {
+		t.Fatal("expected at least one embedded swagger file")
+	}
+	for _, v := range versions {
+		if !Has(v) {
+			t.Errorf("version %q was listed but Has returned false", v)
+		}
+	}
+	if Has("0.1") {
+		t.Error("did not expect a swagger file for version 0.1")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("an embedded version is valid json", func(t *testing.T) {
+		f, err := Open("1.12")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		swagger := struct {
+			Definitions map[string]interface{}
+		}{}
+		if err := json.NewDecoder(f).Decode(&swagger); err != nil {
+			t.Fatal(err)
+		}
+		if len(swagger.Definitions) == 0 {
+			t.Fatal("expected definitions in the swagger file")
+		}
+	})
+
+	t.Run("an unknown version is an error", func(t *testing.T) {
+		if _, err := Open("0.1"); err == nil {
+			t.Fatal("expected an error but got nil")
+		}
+	})
+}
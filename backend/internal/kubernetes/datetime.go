@@ -0,0 +1,15 @@
+package kubernetes
+
+import "github.com/chuckha/kubeyaml.com/backend/internal/shared/datetime"
+
+// validateDateTime checks a date-time the way metav1.Time and metav1.MicroTime parse them.
+func validateDateTime(key, ref string, value interface{}) error {
+	date, ok := value.(string)
+	if !ok {
+		return NewWrongTypeError(key, "string", value)
+	}
+	if !datetime.Valid(ref, date) {
+		return NewInvalidDateTimeError(key, date, datetime.Layout(ref))
+	}
+	return nil
+}
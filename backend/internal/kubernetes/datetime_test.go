@@ -0,0 +1,53 @@
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+)
+
+func TestDateTime(t *testing.T) {
+	const (
+		timeRef      = "io.k8s.apimachinery.pkg.apis.meta.v1.Time"
+		microTimeRef = "io.k8s.apimachinery.pkg.apis.meta.v1.MicroTime"
+	)
+	v := kubernetes.NewValidator(&resolver{
+		lookup: &kubernetes.Swagger{
+			Definitions: map[string]*kubernetes.Schema{
+				timeRef:      &kubernetes.Schema{Type: "string", Format: "date-time"},
+				microTimeRef: &kubernetes.Schema{Type: "string", Format: "date-time"},
+			},
+		},
+		version: "v1.12",
+	})
+	schema := &kubernetes.Schema{
+		Properties: map[string]*kubernetes.Property{
+			"lastTransitionTime": &kubernetes.Property{Reference: timeRef},
+			"renewTime":          &kubernetes.Property{Reference: microTimeRef},
+		},
+	}
+
+	testcases := []struct {
+		key    string
+		value  interface{}
+		errors int
+	}{
+		{key: "lastTransitionTime", value: "2019-10-12T07:20:50Z"},
+		{key: "lastTransitionTime", value: "2019-10-12T07:20:50.52Z"},
+		{key: "lastTransitionTime", value: "2019-10-12T07:20:50.123456789+02:00"},
+		{key: "lastTransitionTime", value: "2019-10-12T07:20:50-08:00"},
+		{key: "lastTransitionTime", value: nil},
+		{key: "lastTransitionTime", value: "2019-10-12 07:20:50", errors: 1},
+		{key: "lastTransitionTime", value: "2019-10-12T07:20:50", errors: 1},
+		{key: "lastTransitionTime", value: 1570864850, errors: 1},
+		{key: "renewTime", value: "2019-10-12T07:20:50.123456Z"},
+		{key: "renewTime", value: "2019-10-12T07:20:50.123456+02:00"},
+		{key: "renewTime", value: "2019-10-12T07:20:50Z", errors: 1},
+	}
+	for _, tc := range testcases {
+		errs := v.Validate(map[interface{}]interface{}{tc.key: tc.value}, schema)
+		if len(errs) != tc.errors {
+			t.Errorf("%s: %v: expected %d errors but got %v", tc.key, tc.value, tc.errors, errs)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package kubernetes
+
+import "github.com/chuckha/kubeyaml.com/backend/internal/shared/deprecation"
+
+// NewDeprecationIndex records which group/version/kinds every kubernetes version serves so deprecated and removed apiVersions can be found.
+// It parses the swagger file of every version, versions that aren't validated against are still worth comparing.
+func NewDeprecationIndex(versions ...string) (*deprecation.Index, error) {
+	index := deprecation.NewIndex()
+	for _, version := range versions {
+		resolver, err := NewResolver(version)
+		if err != nil {
+			return nil, err
+		}
+		gvks, err := resolver.GroupVersionKinds()
+		if err != nil {
+			return nil, err
+		}
+		index.Add(version, gvks...)
+	}
+	return index, nil
+}
+
+// GroupVersionKinds returns every group/version/kind the swagger file of the resolver's version serves.
+func (r *Resolver) GroupVersionKinds() ([]deprecation.GVK, error) {
+	swagger, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	return swagger.GroupVersionKinds(), nil
+}
+
+// GroupVersionKinds returns every group/version/kind that has a definition.
+func (s *Swagger) GroupVersionKinds() []deprecation.GVK {
+	gvks := make([]deprecation.GVK, 0, len(s.Definitions))
+	for _, def := range s.Definitions {
+		for _, gvk := range def.GVK {
+			gvks = append(gvks, deprecation.GVK{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind})
+		}
+	}
+	return gvks
+}
+
+// CheckAPIVersion returns a DeprecatedAPIError or a RemovedAPIError when apiVersion and kind are deprecated or removed in version.
+// It returns nil when they are fine or the index is nil.
+func CheckAPIVersion(index *deprecation.Index, version, apiVersion, kind string) error {
+	return lifecycleError(index.LookupAPIVersion(version, apiVersion, kind))
+}
+
+// lifecycleError turns the status of an apiVersion into an error of this package, it's nil when status is.
+func lifecycleError(status *deprecation.Status) error {
+	if status == nil {
+		return nil
+	}
+	apiVersion, kind := status.GVK.APIVersion(), status.GVK.Kind
+	if status.RemovedIn != "" {
+		return NewRemovedAPIError(apiVersion, kind, status.RemovedIn, status.ReplacementAPIVersion())
+	}
+	return NewDeprecatedAPIError(apiVersion, kind, status.ReplacementAPIVersion())
+}
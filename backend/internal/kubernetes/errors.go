@@ -4,28 +4,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/datetime"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/position"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/problem"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/quantity"
 )
 
 // RequiredKeyNotFoundError is an error representing a required schema key that is missing
 type RequiredKeyNotFoundError struct {
 	key  string
 	path []string
+	// Position is the span of the object that is missing the key.
+	Position *position.Position
 }
 
 // NewRequiredKeyNotFoundError returns a new RequiredKeyNotFoundError
 func NewRequiredKeyNotFoundError(key string, path []string) error {
-	return &RequiredKeyNotFoundError{key: key, path: path}
-}
-
-// MarshalJSON extracts the error since error is an interface
-func (r *RequiredKeyNotFoundError) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Key   string
-		Error string
-	}{
-		Key:   strings.Join(r.path, "."),
-		Error: "Missing required key: " + r.key,
-	})
+	return &RequiredKeyNotFoundError{key: key, path: append([]string{}, path...)}
 }
 
 // Error implements the error interface
@@ -64,17 +60,6 @@ func (u *UnknownSchemaError) Error() string {
 	return fmt.Sprintf("unknown schema %v", u.schemaKey)
 }
 
-// MarshalJSON is used for the json.Marshal call.
-func (u *UnknownSchemaError) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Error string
-		Key   string
-	}{
-		Error: u.Error(),
-		Key:   "apiVersion",
-	})
-}
-
 type KeyNotStringError struct {
 	key interface{}
 }
@@ -88,13 +73,22 @@ func (k *KeyNotStringError) Error() string {
 
 type UnknownKeyError struct {
 	key string
+	// Suggestions are the keys that were most likely meant, best first.
+	Suggestions []string
 }
 
-func NewUnknownKeyError(key string) error {
-	return &UnknownKeyError{key: key}
+func NewUnknownKeyError(key string, suggestions ...string) error {
+	return &UnknownKeyError{key: key, Suggestions: suggestions}
 }
 func (u *UnknownKeyError) Error() string {
-	return fmt.Sprintf("unknown key: %v", u.key)
+	switch len(u.Suggestions) {
+	case 0:
+		return fmt.Sprintf("unknown key: %v", u.key)
+	case 1:
+		return fmt.Sprintf("unknown key: %v (did you mean %v?)", u.key, u.Suggestions[0])
+	}
+	last := len(u.Suggestions) - 1
+	return fmt.Sprintf("unknown key: %v (did you mean %v or %v?)", u.key, strings.Join(u.Suggestions[:last], ", "), u.Suggestions[last])
 }
 
 type WrongTypeError struct {
@@ -111,13 +105,34 @@ func NewWrongTypeError(k, expectedType string, v interface{}) error {
 	}
 }
 func (w *WrongTypeError) Error() string {
-	return fmt.Sprintf("key %v has wrong type %T (should be %s)", w.key, w.value, w.expectedType)
+	return fmt.Sprintf("key %v has wrong type %s (should be %s)", w.key, problem.TypeName(w.value), w.expectedType)
+}
+
+// NotInEnumError means a value is not one of the values the schema allows.
+type NotInEnumError struct {
+	key     string
+	value   interface{}
+	allowed []interface{}
+}
+
+func NewNotInEnumError(key string, value interface{}, allowed []interface{}) error {
+	return &NotInEnumError{
+		key:     key,
+		value:   value,
+		allowed: allowed,
+	}
+}
+func (n *NotInEnumError) Error() string {
+	return fmt.Sprintf("key %v has value %v (should be one of %v)", n.key, n.value, n.allowed)
 }
 
 type YamlPathError struct {
 	Err   error
 	Value interface{} `json:",omitempty"`
 	Path  string
+	// Position is the span of the offending key or value in the document.
+	Position *position.Position `json:",omitempty"`
+	path     []string
 }
 
 func NewYamlPathError(path []string, value interface{}, err error) error {
@@ -125,23 +140,119 @@ func NewYamlPathError(path []string, value interface{}, err error) error {
 		Err:   err,
 		Value: value,
 		Path:  strings.Join(path, "."),
+		path:  append([]string{}, path...),
 	}
 }
 func (y *YamlPathError) Error() string {
 	return fmt.Sprintf("[%s] %v", y.Path, y.Err)
 }
 
-// MarshalJSON extracts the error since error is an interface
-func (y *YamlPathError) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		Key   string
-		Value interface{}
-		Error string
-	}{
-		Key:   y.Path,
-		Value: y.Value,
-		Error: y.Err.Error(),
-	})
+// OutOfRangeError means a number does not fit the format of its field, such as an int32.
+type OutOfRangeError struct {
+	key    string
+	format string
+	value  interface{}
+}
+
+func NewOutOfRangeError(key, format string, value interface{}) error {
+	return &OutOfRangeError{
+		key:    key,
+		format: format,
+		value:  value,
+	}
+}
+func (o *OutOfRangeError) Error() string {
+	return fmt.Sprintf("key %v has value %v which is out of range for %s", o.key, o.value, o.format)
+}
+
+// InvalidQuantityError means a resource quantity, such as a memory limit, can't be parsed.
+type InvalidQuantityError struct {
+	key   string
+	value string
+}
+
+func NewInvalidQuantityError(key, value string) error {
+	return &InvalidQuantityError{
+		key:   key,
+		value: value,
+	}
+}
+func (i *InvalidQuantityError) Error() string {
+	return fmt.Sprintf("key %v has invalid quantity %q (should be %s)", i.key, i.value, quantity.Grammar)
+}
+
+// InvalidDateTimeError means a timestamp is not in the RFC 3339 layout kubernetes expects.
+type InvalidDateTimeError struct {
+	key    string
+	value  string
+	layout string
+}
+
+func NewInvalidDateTimeError(key, value, layout string) error {
+	return &InvalidDateTimeError{
+		key:    key,
+		value:  value,
+		layout: layout,
+	}
+}
+func (i *InvalidDateTimeError) Error() string {
+	example := "2006-01-02T15:04:05Z or 2006-01-02T15:04:05.999+07:00"
+	if i.layout == datetime.RFC3339Micro {
+		example = "2006-01-02T15:04:05.000000Z, with exactly six fractional digits"
+	}
+	return fmt.Sprintf("key %v has invalid date-time %q (should be RFC 3339 such as %s)", i.key, i.value, example)
+}
+
+// InvalidMetadataError is a schema-valid value in ObjectMeta that the API server rejects, such as a name with upper case letters.
+type InvalidMetadataError struct {
+	field    string
+	value    string
+	messages []string
+	// key is true when the problem is a map key, like a label key, rather than a value.
+	key bool
+}
+
+func NewInvalidMetadataError(field, value string, messages ...string) error {
+	return &InvalidMetadataError{
+		field:    field,
+		value:    value,
+		messages: messages,
+	}
+}
+
+// NewInvalidMetadataKeyError is an InvalidMetadataError that points at a key in the document instead of a value.
+func NewInvalidMetadataKeyError(field, key string, messages ...string) error {
+	return &InvalidMetadataError{
+		field:    field,
+		value:    key,
+		messages: messages,
+		key:      true,
+	}
+}
+func (i *InvalidMetadataError) Error() string {
+	if i.value == "" {
+		return fmt.Sprintf("%s %s", i.field, strings.Join(i.messages, ", "))
+	}
+	return fmt.Sprintf("%s %q %s", i.field, i.value, strings.Join(i.messages, ", "))
+}
+
+// DuplicateKeyError means a key appears twice in the same mapping. Only the value of the second one is kept.
+type DuplicateKeyError struct {
+	key string
+	// First and Second are where the key is defined.
+	First  *position.Position
+	Second *position.Position
+}
+
+func NewDuplicateKeyError(key string, first, second *position.Position) error {
+	return &DuplicateKeyError{
+		key:    key,
+		First:  first,
+		Second: second,
+	}
+}
+func (d *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %v: defined at %v and again at %v, only the last one is used", d.key, d.First, d.Second)
 }
 
 type UnknownFormatError struct {
@@ -156,3 +267,283 @@ func NewUnknownFormatError(format string) error {
 		Format: format,
 	}
 }
+
+// MissingResourcesError is a container without resource limits or requests.
+// Without limits a container can starve its neighbours, without requests the scheduler assumes it needs nothing.
+type MissingResourcesError struct {
+	container string
+	// field is limits or requests.
+	field string
+}
+
+func NewMissingResourcesError(container, field string) error {
+	return &MissingResourcesError{
+		container: container,
+		field:     field,
+	}
+}
+func (m *MissingResourcesError) Error() string {
+	return fmt.Sprintf("container %v has no resource %s", m.container, m.field)
+}
+
+// LatestImageTagError is an image pulled by the latest tag, so what runs changes whenever the image is pushed.
+type LatestImageTagError struct {
+	image string
+}
+
+func NewLatestImageTagError(image string) error {
+	return &LatestImageTagError{
+		image: image,
+	}
+}
+func (l *LatestImageTagError) Error() string {
+	return fmt.Sprintf("image %v uses the latest tag, pin a version or digest instead", l.image)
+}
+
+// DeprecatedAPIError is an apiVersion and kind that still work but have a stable replacement.
+type DeprecatedAPIError struct {
+	apiVersion  string
+	kind        string
+	replacement string
+}
+
+func NewDeprecatedAPIError(apiVersion, kind, replacement string) error {
+	return &DeprecatedAPIError{
+		apiVersion:  apiVersion,
+		kind:        kind,
+		replacement: replacement,
+	}
+}
+func (d *DeprecatedAPIError) Error() string {
+	return fmt.Sprintf("%s %s is deprecated, use %s", d.apiVersion, d.kind, d.replacement)
+}
+
+// RemovedAPIError is an apiVersion and kind that an older kubernetes version served and this one doesn't.
+type RemovedAPIError struct {
+	apiVersion string
+	kind       string
+	removedIn  string
+	// replacement is empty when there is nothing to use instead.
+	replacement string
+}
+
+func NewRemovedAPIError(apiVersion, kind, removedIn, replacement string) error {
+	return &RemovedAPIError{
+		apiVersion:  apiVersion,
+		kind:        kind,
+		removedIn:   removedIn,
+		replacement: replacement,
+	}
+}
+func (r *RemovedAPIError) Error() string {
+	if r.replacement == "" {
+		return fmt.Sprintf("%s %s was removed in %s", r.apiVersion, r.kind, r.removedIn)
+	}
+	return fmt.Sprintf("%s %s was removed in %s, use %s", r.apiVersion, r.kind, r.removedIn, r.replacement)
+}
+
+// Locate sets the position of every error that knows its path.
+// Errors about keys point at the key, everything else points at the value.
+func Locate(index *position.Index, errs ...error) {
+	for _, err := range errs {
+		switch e := err.(type) {
+		case *RequiredKeyNotFoundError:
+			e.Position = index.Key(e.path)
+		case *YamlPathError:
+			switch e.Err.(type) {
+			case *UnknownKeyError, *KeyNotStringError:
+				e.Position = index.Key(e.path)
+			case *DuplicateKeyError:
+				e.Position = e.Err.(*DuplicateKeyError).Second
+			case *InvalidMetadataError:
+				if e.Err.(*InvalidMetadataError).key {
+					e.Position = index.Key(e.path)
+				} else {
+					e.Position = index.Value(e.path)
+				}
+			default:
+				e.Position = index.Value(e.path)
+			}
+		}
+	}
+}
+
+// Problem is implemented by every error the validator returns.
+// The Detail of an error is also what it serializes to.
+type Problem interface {
+	error
+	Detail() *problem.Detail
+}
+
+// DetailOf returns the Detail of any error, errors that are not a Problem get the unknown code.
+func DetailOf(err error) *problem.Detail {
+	if p, ok := err.(Problem); ok {
+		return p.Detail()
+	}
+	return problem.New(problem.CodeUnknown, err.Error())
+}
+
+// SeverityOf is the severity of any error, errors that are not a Problem are errors.
+func SeverityOf(err error) problem.Severity {
+	return DetailOf(err).Severity
+}
+
+// FilterSeverity returns the errors that are at least as bad as min.
+func FilterSeverity(min problem.Severity, errs ...error) []error {
+	filtered := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if SeverityOf(err).AtLeast(min) {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
+
+func (r *RequiredKeyNotFoundError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeRequiredKeyNotFound, "Missing required key: "+r.key)
+	d.Path = strings.Join(r.path, ".")
+	d.Expected = r.key
+	d.Position = r.Position
+	return d
+}
+func (r *RequiredKeyNotFoundError) MarshalJSON() ([]byte, error) { return json.Marshal(r.Detail()) }
+
+func (u *UnknownTypeError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeUnknownType, u.Error())
+	d.Expected = "string"
+	d.Actual = problem.TypeName(u.t)
+	return d
+}
+func (u *UnknownTypeError) MarshalJSON() ([]byte, error) { return json.Marshal(u.Detail()) }
+
+func (u *UnknownSchemaError) Detail() *problem.Detail {
+	return problem.New(problem.CodeUnknownSchema, u.Error())
+}
+func (u *UnknownSchemaError) MarshalJSON() ([]byte, error) { return json.Marshal(u.Detail()) }
+
+func (k *KeyNotStringError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeKeyNotString, k.Error())
+	d.Expected = "string"
+	d.Actual = problem.TypeName(k.key)
+	return d
+}
+func (k *KeyNotStringError) MarshalJSON() ([]byte, error) { return json.Marshal(k.Detail()) }
+
+func (u *UnknownKeyError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeUnknownKey, u.Error())
+	d.Suggestions = u.Suggestions
+	return d
+}
+func (u *UnknownKeyError) MarshalJSON() ([]byte, error) { return json.Marshal(u.Detail()) }
+
+func (w *WrongTypeError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeWrongType, w.Error())
+	d.Expected = w.expectedType
+	d.Actual = problem.TypeName(w.value)
+	return d
+}
+func (w *WrongTypeError) MarshalJSON() ([]byte, error) { return json.Marshal(w.Detail()) }
+
+func (n *NotInEnumError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeNotInEnum, n.Error())
+	d.Expected = fmt.Sprint(n.allowed)
+	d.Actual = fmt.Sprint(n.value)
+	return d
+}
+func (n *NotInEnumError) MarshalJSON() ([]byte, error) { return json.Marshal(n.Detail()) }
+
+func (o *OutOfRangeError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeOutOfRange, o.Error())
+	d.Expected = o.format
+	d.Actual = fmt.Sprint(o.value)
+	return d
+}
+func (o *OutOfRangeError) MarshalJSON() ([]byte, error) { return json.Marshal(o.Detail()) }
+
+func (i *InvalidQuantityError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeInvalidQuantity, i.Error())
+	d.Expected = "quantity"
+	d.Actual = i.value
+	return d
+}
+func (i *InvalidQuantityError) MarshalJSON() ([]byte, error) { return json.Marshal(i.Detail()) }
+
+func (i *InvalidDateTimeError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeInvalidDateTime, i.Error())
+	d.Expected = i.layout
+	d.Actual = i.value
+	return d
+}
+func (i *InvalidDateTimeError) MarshalJSON() ([]byte, error) { return json.Marshal(i.Detail()) }
+
+func (i *InvalidMetadataError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeInvalidMetadata, i.Error())
+	d.Actual = i.value
+	return d
+}
+func (i *InvalidMetadataError) MarshalJSON() ([]byte, error) { return json.Marshal(i.Detail()) }
+
+func (d *DuplicateKeyError) Detail() *problem.Detail {
+	detail := problem.New(problem.CodeDuplicateKey, d.Error())
+	detail.Position = d.Second
+	return detail
+}
+func (d *DuplicateKeyError) MarshalJSON() ([]byte, error) { return json.Marshal(d.Detail()) }
+
+func (u *UnknownFormatError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeUnknownFormat, u.Error())
+	d.Actual = u.Format
+	return d
+}
+func (u *UnknownFormatError) MarshalJSON() ([]byte, error) { return json.Marshal(u.Detail()) }
+
+func (m *MissingResourcesError) Detail() *problem.Detail {
+	if m.field == "requests" {
+		d := problem.New(problem.CodeMissingResourceRequests, m.Error())
+		d.Severity = problem.SeverityInfo
+		return d
+	}
+	d := problem.New(problem.CodeMissingResourceLimits, m.Error())
+	d.Severity = problem.SeverityWarning
+	return d
+}
+func (m *MissingResourcesError) MarshalJSON() ([]byte, error) { return json.Marshal(m.Detail()) }
+
+func (l *LatestImageTagError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeLatestImageTag, l.Error())
+	d.Severity = problem.SeverityWarning
+	d.Actual = l.image
+	return d
+}
+func (l *LatestImageTagError) MarshalJSON() ([]byte, error) { return json.Marshal(l.Detail()) }
+
+func (d *DeprecatedAPIError) Detail() *problem.Detail {
+	detail := problem.New(problem.CodeDeprecatedAPI, d.Error())
+	detail.Severity = problem.SeverityWarning
+	detail.Expected = d.replacement
+	detail.Actual = d.apiVersion
+	return detail
+}
+func (d *DeprecatedAPIError) MarshalJSON() ([]byte, error) { return json.Marshal(d.Detail()) }
+
+func (r *RemovedAPIError) Detail() *problem.Detail {
+	d := problem.New(problem.CodeRemovedAPI, r.Error())
+	d.Expected = r.replacement
+	d.Actual = r.apiVersion
+	return d
+}
+func (r *RemovedAPIError) MarshalJSON() ([]byte, error) { return json.Marshal(r.Detail()) }
+
+// Detail is the Detail of the wrapped error at the path of the YamlPathError.
+func (y *YamlPathError) Detail() *problem.Detail {
+	d := DetailOf(y.Err)
+	d.Path = y.Path
+	d.Value = y.Value
+	if y.Position != nil {
+		d.Position = y.Position
+	}
+	return d
+}
+
+// MarshalJSON extracts the error since error is an interface
+func (y *YamlPathError) MarshalJSON() ([]byte, error) { return json.Marshal(y.Detail()) }
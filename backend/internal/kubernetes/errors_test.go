@@ -0,0 +1,85 @@
+package kubernetes_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+)
+
+func TestErrorJSON(t *testing.T) {
+	testcases := []struct {
+		name     string
+		err      error
+		expected map[string]interface{}
+	}{
+		{
+			name: "unwrapped unknown key",
+			err:  kubernetes.NewUnknownKeyError("contianers", "containers"),
+			expected: map[string]interface{}{
+				"Code":        "KY001",
+				"Kind":        "unknown-key",
+				"Severity":    "error",
+				"Key":         "",
+				"Error":       "unknown key: contianers (did you mean containers?)",
+				"Suggestions": []interface{}{"containers"},
+			},
+		},
+		{
+			name: "wrong type at a path",
+			err:  kubernetes.NewYamlPathError([]string{"spec", "replicas"}, "three", kubernetes.NewWrongTypeError("replicas", "integer", "three")),
+			expected: map[string]interface{}{
+				"Code":     "KY003",
+				"Kind":     "wrong-type",
+				"Severity": "error",
+				"Key":      "spec.replicas",
+				"Error":    "key replicas has wrong type string (should be integer)",
+				"Expected": "integer",
+				"Actual":   "string",
+				"Value":    "three",
+			},
+		},
+		{
+			name: "unknown schema has no made up path",
+			err:  kubernetes.NewUnknownSchemaError("io.k8s.api.foo.v1.Bar"),
+			expected: map[string]interface{}{
+				"Code":     "KY004",
+				"Kind":     "unknown-schema",
+				"Severity": "error",
+				"Key":      "",
+				"Error":    "unknown schema io.k8s.api.foo.v1.Bar",
+			},
+		},
+		{
+			name: "required key",
+			err:  kubernetes.NewRequiredKeyNotFoundError("containers", []string{"spec"}),
+			expected: map[string]interface{}{
+				"Code":     "KY002",
+				"Kind":     "required-key-not-found",
+				"Severity": "error",
+				"Key":      "spec",
+				"Error":    "Missing required key: containers",
+				"Expected": "containers",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, ok := tc.err.(kubernetes.Problem); !ok {
+				t.Fatalf("expected %T to be a Problem", tc.err)
+			}
+			b, err := json.Marshal(tc.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := map[string]interface{}{}
+			if err := json.Unmarshal(b, &actual); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explanation documents a field of a kubernetes object and the fields under it, like `kubectl explain`.
+type Explanation struct {
+	// Version is the kubernetes version the documentation comes from.
+	Version string
+	// Path is the dotted path to the field, it's empty for the object itself.
+	Path        string
+	Type        string
+	Description string
+	Required    bool
+	// Fields are the fields the field can have sorted by name, objects have them but strings and maps don't.
+	Fields []*FieldSummary
+}
+
+// FieldSummary is what an explanation says about each of the fields under the field being explained.
+type FieldSummary struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// Explain documents the field at path in the schema with schemaKey.
+func (v *Validator) Explain(schemaKey string, path []string) (*Explanation, error) {
+	field, err := v.Lookup(schemaKey, path)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := v.Fields(field)
+	if err != nil {
+		return nil, err
+	}
+	explanation := &Explanation{
+		Version:     v.Version(),
+		Path:        strings.Join(path, "."),
+		Type:        field.Type(),
+		Description: field.Description(),
+		Required:    field.Required,
+		Fields:      make([]*FieldSummary, len(fields)),
+	}
+	for i, f := range fields {
+		explanation.Fields[i] = &FieldSummary{
+			Name:        f.Name,
+			Type:        f.Type(),
+			Description: f.Property.Description,
+			Required:    f.Required,
+		}
+	}
+	return explanation, nil
+}
+
+// Explainer documents fields in the schemas of several kubernetes versions.
+type Explainer struct {
+	finder     KeyFinder
+	validators []*Validator
+}
+
+// NewExplainer returns an Explainer for the versions of the validators. The first one is used when no version is asked for.
+func NewExplainer(finder KeyFinder, validators ...*Validator) *Explainer {
+	return &Explainer{
+		finder:     finder,
+		validators: validators,
+	}
+}
+
+// Explain documents the field at path in the schema of apiVersion and kind in a kubernetes version.
+// The first version is used when version is empty, an unknown version is an UnknownVersionError.
+func (e *Explainer) Explain(version, apiVersion, kind string, path []string) (*Explanation, error) {
+	for _, v := range e.validators {
+		if version == "" || v.Version() == version {
+			return v.Explain(e.finder.APIKey(apiVersion, kind), path)
+		}
+	}
+	return nil, NewUnknownVersionError(version)
+}
+
+// UnknownVersionError means a kubernetes version was asked for that there's no schema for.
+type UnknownVersionError struct {
+	Version string
+}
+
+// NewUnknownVersionError returns an UnknownVersionError.
+func NewUnknownVersionError(version string) error {
+	return &UnknownVersionError{Version: version}
+}
+
+// Error implements the error interface.
+func (u *UnknownVersionError) Error() string {
+	return fmt.Sprintf("unknown kubernetes version %q", u.Version)
+}
@@ -0,0 +1,64 @@
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+)
+
+func TestExplain(t *testing.T) {
+	resolver, err := kubernetes.NewResolver("1.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := kubernetes.NewValidator(resolver)
+	keyer := kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io")
+
+	explanation, err := v.Explain(keyer.APIKey("apps/v1", "Deployment"), []string{"spec", "template", "spec", "containers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if explanation.Version != "1.12" || explanation.Path != "spec.template.spec.containers" || explanation.Type != "[]Container" || !explanation.Required {
+		t.Fatalf("unexpected explanation %+v", explanation)
+	}
+	var name *kubernetes.FieldSummary
+	for _, f := range explanation.Fields {
+		if f.Name == "name" {
+			name = f
+		}
+	}
+	if name == nil || name.Type != "string" || !name.Required || name.Description == "" {
+		t.Fatalf("expected the required name of a container but got %+v", name)
+	}
+
+	if _, err := v.Explain(keyer.APIKey("apps/v1", "Deploymnt"), nil); err == nil {
+		t.Fatal("expected an error for an unknown kind")
+	}
+}
+
+func TestExplainer(t *testing.T) {
+	validators := make([]*kubernetes.Validator, 0)
+	for _, version := range []string{"1.12", "1.11"} {
+		resolver, err := kubernetes.NewResolver(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		validators = append(validators, kubernetes.NewValidator(resolver))
+	}
+	e := kubernetes.NewExplainer(kubernetes.NewAPIKeyer("io.k8s.api", ".k8s.io"), validators...)
+
+	for version, expected := range map[string]string{"": "1.12", "1.11": "1.11"} {
+		explanation, err := e.Explain(version, "v1", "Pod", []string{"spec"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if explanation.Version != expected || explanation.Type != "PodSpec" {
+			t.Fatalf("expected the PodSpec of %s but got %+v", expected, explanation)
+		}
+	}
+	if _, err := e.Explain("0.1", "v1", "Pod", nil); err == nil {
+		t.Fatal("expected an error for an unknown version")
+	} else if _, ok := err.(*kubernetes.UnknownVersionError); !ok {
+		t.Fatalf("expected an UnknownVersionError but got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package kubernetes
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Field is what the schema says about a single key of a document.
+type Field struct {
+	// Name is the key, it's empty for the top of the document.
+	Name string
+	// Property describes the field. The top of the document is a property that references its schema.
+	Property *Property
+	// Schema is the schema the field references, or the one its items reference for arrays.
+	// It's nil for builtin types such as strings and maps.
+	Schema *Schema
+	// Required is true when the schema holding the field requires it.
+	Required bool
+}
+
+// Type is a short name for the type of the field, such as `string`, `[]Container` or `map[string]string`.
+func (f *Field) Type() string {
+	return typeName(f.Property)
+}
+
+// Description is the description of the property, or of its schema when the property doesn't have one.
+func (f *Field) Description() string {
+	if f.Property.Description != "" || f.Schema == nil {
+		return f.Property.Description
+	}
+	return f.Schema.Description
+}
+
+func typeName(property *Property) string {
+	switch {
+	case property.Reference != "":
+		ref := strings.TrimPrefix(property.Reference, "#/definitions/")
+		return ref[strings.LastIndex(ref, ".")+1:]
+	case property.Type == "array" && property.Items != nil:
+		return "[]" + typeName(property.Items.property())
+	case isMap(property):
+		return "map[string]" + typeName(&property.AdditionalProperties.Property)
+	case property.Type == "":
+		return "object"
+	}
+	return property.Type
+}
+
+func isMap(property *Property) bool {
+	additional := property.AdditionalProperties
+	return property.Type == "object" && additional != nil && (additional.Type != "" || additional.Reference != "")
+}
+
+// Lookup finds the field at path in the schema with schemaKey, following references along the way.
+// Array items can be skipped or given by index, `spec.containers.image` and `spec.containers.0.image` are the same field.
+func (v *Validator) Lookup(schemaKey string, path []string) (*Field, error) {
+	field, err := v.field("", &Property{Reference: schemaKey}, false)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range path {
+		field, err = v.child(field, key)
+		if err != nil {
+			return nil, NewYamlPathError(path[:i+1], "", err)
+		}
+	}
+	return field, nil
+}
+
+// Fields lists the fields an object can have, sorted by name.
+func (v *Validator) Fields(field *Field) ([]*Field, error) {
+	if field.Schema == nil {
+		return []*Field{}, nil
+	}
+	names := propertyNames(field.Schema)
+	fields := make([]*Field, 0, len(names))
+	for _, name := range names {
+		child, err := v.child(field, name)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, child)
+	}
+	return fields, nil
+}
+
+// child is the field found at key inside of parent.
+func (v *Validator) child(parent *Field, key string) (*Field, error) {
+	property := parent.Property
+	if _, err := strconv.Atoi(key); err == nil && property.Type == "array" && property.Items != nil {
+		return v.field(key, property.Items.property(), false)
+	}
+	if isMap(property) {
+		return v.field(key, &property.AdditionalProperties.Property, false)
+	}
+	if parent.Schema == nil {
+		return nil, NewUnknownKeyError(key)
+	}
+	child, ok := parent.Schema.Properties[key]
+	if !ok {
+		return nil, NewUnknownKeyError(key, v.suggestions(key, parent.Schema)...)
+	}
+	return v.field(key, child, isRequired(parent.Schema, key))
+}
+
+func (v *Validator) field(name string, property *Property, required bool) (*Field, error) {
+	field := &Field{Name: name, Property: property, Required: required}
+	ref := property.Reference
+	if property.Type == "array" && property.Items != nil {
+		ref = property.Items.Reference
+	}
+	if ref == "" {
+		return field, nil
+	}
+	schema, err := v.resolver.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	field.Schema = schema
+	return field, nil
+}
+
+func isRequired(schema *Schema, key string) bool {
+	for _, required := range schema.Required {
+		if required == key {
+			return true
+		}
+	}
+	return false
+}
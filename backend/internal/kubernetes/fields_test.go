@@ -0,0 +1,115 @@
+package kubernetes_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+)
+
+func TestLookup(t *testing.T) {
+	resolver, err := kubernetes.NewResolver("1.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := kubernetes.NewValidator(resolver)
+
+	testcases := []struct {
+		name        string
+		path        string
+		typ         string
+		required    bool
+		description string
+		err         string
+	}{
+		{
+			name:        "top of the document",
+			typ:         "Deployment",
+			description: "Deployment enables declarative updates for Pods and ReplicaSets.",
+		},
+		{
+			name:        "reference",
+			path:        "spec.strategy",
+			typ:         "DeploymentStrategy",
+			description: "The deployment strategy to use to replace existing pods with new ones.",
+		},
+		{
+			name:     "required",
+			path:     "spec.template.spec.containers",
+			typ:      "[]Container",
+			required: true,
+		},
+		{
+			name: "array item by index",
+			path: "spec.template.spec.containers.0.image",
+			typ:  "string",
+		},
+		{
+			name:     "array item without an index",
+			path:     "spec.template.spec.containers.ports.containerPort",
+			typ:      "integer",
+			required: true,
+		},
+		{
+			name: "map",
+			path: "metadata.labels",
+			typ:  "map[string]string",
+		},
+		{
+			name: "map value",
+			path: "metadata.labels.app",
+			typ:  "string",
+		},
+		{
+			name: "unknown key",
+			path: "spec.replica",
+			err:  "[spec.replica] unknown key: replica (did you mean replicas?)",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var path []string
+			if tc.path != "" {
+				path = strings.Split(tc.path, ".")
+			}
+			field, err := v.Lookup("io.k8s.api.apps.v1.Deployment", path)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q but got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if field.Type() != tc.typ || field.Required != tc.required {
+				t.Fatalf("expected a %s field (required %v) but got %s (required %v)", tc.typ, tc.required, field.Type(), field.Required)
+			}
+			if tc.description != "" && !strings.HasPrefix(field.Description(), tc.description) {
+				t.Fatalf("expected the description to start with %q but got %q", tc.description, field.Description())
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	resolver, err := kubernetes.NewResolver("1.12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := kubernetes.NewValidator(resolver)
+	field, err := v.Lookup("io.k8s.api.apps.v1.Deployment", []string{"spec", "strategy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, err := v.Fields(field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || fields[0].Name != "rollingUpdate" || fields[1].Name != "type" {
+		t.Fatalf("expected rollingUpdate and type but got %v", fields)
+	}
+	if fields[0].Type() != "RollingUpdateDeployment" || fields[0].Schema == nil {
+		t.Fatalf("expected rollingUpdate to reference its schema but got %s", fields[0].Type())
+	}
+}
@@ -1,18 +1,31 @@
 package kubernetes
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
 
 	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/position"
 )
 
-// Input is the top level YAML document the system receives.
+// Input is a single top level YAML document the system receives.
 type Input struct {
+	// Index is the position of the document in the YAML stream, starting at 0.
+	Index      int
 	Kind       string
 	APIVersion string
 	Data       map[interface{}]interface{}
+	// Positions knows where every key and value of the document is in the stream.
+	Positions *position.Index
+	// Errors are problems found while loading that don't stop the document from being validated, such as duplicate keys.
+	Errors []error
+	// Err is why the document couldn't be loaded, such as a missing kind.
+	// A document with Err has only an Index and Positions and can't be validated.
+	Err error
 }
 
 // Loader defines a struct that can read data from a stream into an internal type.
@@ -23,19 +36,53 @@ func NewLoader() *Loader {
 	return &Loader{}
 }
 
-// Load reads the input and returns the internal type representing the top level document
-// that is properly cleaned.
-func (l *Loader) Load(reader io.Reader) (*Input, error) {
+// Load reads the input and returns one properly cleaned Input for every document in the stream.
+// Documents are separated by `---` and empty documents are skipped, but they still count towards
+// the Index so that the Index always matches the position of the document in the stream.
+// A document without a usable apiVersion or kind has its Err set, the error is only for input that isn't YAML.
+func (l *Loader) Load(reader io.Reader) ([]*Input, error) {
 	b, err := ioutil.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read incoming reader: %v", err)
 	}
 
-	incoming := map[interface{}]interface{}{}
-	if err := yaml.Unmarshal(b, incoming); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal yaml with error %v", err)
+	inputs := make([]*Input, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	// yaml.v2 gives us the values the validator works with and yaml.v3 tells us where they are.
+	nodes := yamlv3.NewDecoder(bytes.NewReader(b))
+	for i := 0; ; i++ {
+		incoming := map[interface{}]interface{}{}
+		if err := decoder.Decode(&incoming); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to unmarshal yaml with error %v", err)
+		}
+		var positions *position.Index
+		node := &yamlv3.Node{}
+		if err := nodes.Decode(node); err == nil {
+			positions = position.NewIndex(node, b)
+		}
+		if len(incoming) == 0 {
+			continue
+		}
+
+		input, err := loadDocument(incoming)
+		if err != nil {
+			// the other documents can still be validated
+			Locate(positions, err)
+			input = &Input{Err: err}
+		}
+		input.Index = i
+		input.Positions = positions
+		input.Errors = duplicateKeyErrors(positions)
+		inputs = append(inputs, input)
 	}
+	return inputs, nil
+}
 
+// loadDocument pulls the apiVersion and kind out of a single document.
+func loadDocument(incoming map[interface{}]interface{}) (*Input, error) {
 	val, ok := incoming["apiVersion"]
 	if !ok {
 		return nil, NewRequiredKeyNotFoundError("apiVersion", []string{"apiVersion"})
@@ -64,3 +111,13 @@ func (l *Loader) Load(reader io.Reader) (*Input, error) {
 		Data:       incoming,
 	}, nil
 }
+
+// duplicateKeyErrors turns every duplicated key in a document into an error at the key that wins.
+func duplicateKeyErrors(positions *position.Index) []error {
+	errs := make([]error, 0)
+	for _, d := range positions.Duplicates() {
+		errs = append(errs, NewYamlPathError(d.Path, "", NewDuplicateKeyError(d.Path[len(d.Path)-1], d.First, d.Second)))
+	}
+	Locate(positions, errs...)
+	return errs
+}
@@ -19,39 +19,45 @@ func TestLoader(t *testing.T) {
 	testcases := []struct {
 		name  string
 		input io.Reader
-		check func(i *kubernetes.Input, err error, t *testing.T)
+		check func(i []*kubernetes.Input, err error, t *testing.T)
 	}{
 		{
 			name:  "no apiVersion",
 			input: strings.NewReader(`kind: Deployment`),
-			check: func(i *kubernetes.Input, err error, t *testing.T) {
-				if err == nil {
-					t.Fatalf("expected an error but got nil")
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
+				if err != nil {
+					t.Fatalf("expected the error on the document but got: %v", err)
+				}
+				if len(i) != 1 || i[0].Err == nil {
+					t.Fatalf("expected a document with an error but got %v", i)
 				}
 			},
 		},
 		{
 			name:  "bad apiVersion",
 			input: strings.NewReader(`apiVersion: 999`),
-			check: func(i *kubernetes.Input, err error, t *testing.T) {
-				if err == nil {
-					t.Fatalf("expected an error but got nil")
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
+				if err != nil {
+					t.Fatalf("expected the error on the document but got: %v", err)
+				}
+				if len(i) != 1 || i[0].Err == nil {
+					t.Fatalf("expected a document with an error but got %v", i)
 				}
 			},
 		},
 		{
 			name:  "reader blows up",
 			input: &badReader{},
-			check: func(i *kubernetes.Input, err error, t *testing.T) {
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
 				if err == nil {
 					t.Fatalf("expected an error but got nil")
 				}
 			},
 		},
 		{
-			name: "bad yaml should throw an error",
+			name:  "bad yaml should throw an error",
 			input: strings.NewReader("	a tab"),
-			check: func(i *kubernetes.Input, err error, t *testing.T) {
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
 				if err == nil {
 					t.Errorf("expected an error but did not get one")
 				}
@@ -83,12 +89,117 @@ spec:
         image: nginx:1.7.9
         ports:
         - containerPort: 80`),
-			check: func(i *kubernetes.Input, err error, t *testing.T) {
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
 				if err != nil {
 					t.Fatalf("expected no error but got: %v", err)
 				}
 			},
 		},
+		{
+			name: "multiple documents",
+			input: strings.NewReader(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc
+---
+`),
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
+				if err != nil {
+					t.Fatalf("expected no error but got: %v", err)
+				}
+				if len(i) != 2 {
+					t.Fatalf("expected 2 documents but got %d", len(i))
+				}
+				if i[0].Index != 0 || i[0].Kind != "ConfigMap" {
+					t.Errorf("expected ConfigMap at index 0 but got %s at index %d", i[0].Kind, i[0].Index)
+				}
+				if i[1].Index != 2 || i[1].Kind != "Service" {
+					t.Errorf("expected Service at index 2 but got %s at index %d", i[1].Kind, i[1].Index)
+				}
+			},
+		},
+		{
+			name: "error in a later document",
+			input: strings.NewReader(`apiVersion: v1
+kind: ConfigMap
+---
+kind: Service
+---
+apiVersion: v1
+kind: Secret`),
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
+				if err != nil {
+					t.Fatalf("expected the other documents to load but got: %v", err)
+				}
+				if len(i) != 3 {
+					t.Fatalf("expected 3 documents but got %d", len(i))
+				}
+				if i[0].Err != nil || i[2].Err != nil {
+					t.Errorf("expected only document 1 to have an error but got %v and %v", i[0].Err, i[2].Err)
+				}
+				if _, ok := i[1].Err.(*kubernetes.RequiredKeyNotFoundError); !ok {
+					t.Errorf("expected a *kubernetes.RequiredKeyNotFoundError in document 1 but got %T", i[1].Err)
+				}
+				if i[1].Index != 1 || i[2].Index != 2 || i[2].Kind != "Secret" {
+					t.Errorf("expected document 1 to have the error and document 2 to be a Secret but got %+v and %+v", i[1], i[2])
+				}
+			},
+		},
+		{
+			name: "errors know where they are",
+			input: strings.NewReader(`apiVersion: v1
+kind: Pod
+---
+kind: Pod
+apiVersion: 3`),
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				pathErr, ok := i[1].Err.(*kubernetes.YamlPathError)
+				if !ok {
+					t.Fatalf("expected a *kubernetes.YamlPathError but got %T", i[1].Err)
+				}
+				if pathErr.Position == nil {
+					t.Fatalf("expected the error to have a position")
+				}
+				if pathErr.Position.Line != 5 || pathErr.Position.Column != 13 {
+					t.Errorf("expected the error at 5:13 but found %v", pathErr.Position)
+				}
+			},
+		},
+		{
+			name: "duplicate keys",
+			input: strings.NewReader(`apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: web
+    env: []
+    env: []`),
+			check: func(i []*kubernetes.Input, err error, t *testing.T) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(i[0].Errors) != 1 {
+					t.Fatalf("expected 1 duplicate key error but got %v", i[0].Errors)
+				}
+				pathErr := i[0].Errors[0].(*kubernetes.YamlPathError)
+				dupErr, ok := pathErr.Err.(*kubernetes.DuplicateKeyError)
+				if !ok {
+					t.Fatalf("expected a *kubernetes.DuplicateKeyError but got %T", pathErr.Err)
+				}
+				if dupErr.First.Line != 6 || dupErr.Second.Line != 7 || pathErr.Position.Line != 7 {
+					t.Errorf("expected the key on lines 6 and 7 but got %v and %v", dupErr.First, dupErr.Second)
+				}
+			},
+		},
 	}
 
 	l := kubernetes.NewLoader()
@@ -6,6 +6,7 @@ import "strings"
 type APIKeyer struct {
 	namespace string
 	suffix    string
+	custom    *CustomResources
 }
 
 // NewAPIKeyer returns an APIKeyer that finds keys with a common prefix.
@@ -16,8 +17,19 @@ func NewAPIKeyer(ns, suffix string) *APIKeyer {
 	}
 }
 
+// AddCustomResources makes the keyer find the keys of custom resources.
+func (g *APIKeyer) AddCustomResources(custom *CustomResources) {
+	g.custom = custom
+}
+
 // APIKey returns the key of the API object as listed in the swagger definition.
 func (g *APIKeyer) APIKey(apiVersion, kind string) string {
+	if g.custom != nil {
+		if key, ok := g.custom.APIKey(apiVersion, kind); ok {
+			return key
+		}
+	}
+
 	if apiVersion == "v1" {
 		apiVersion = "core/v1"
 	}
@@ -0,0 +1,26 @@
+package kubernetes
+
+import "github.com/chuckha/kubeyaml.com/backend/internal/shared/objectmeta"
+
+// hasObjectMeta is true when the metadata of a schema is an ObjectMeta, lists have a ListMeta instead.
+func hasObjectMeta(schema *Schema) bool {
+	if schema == nil {
+		return false
+	}
+	metadata, ok := schema.Properties["metadata"]
+	return ok && objectmeta.IsRef(metadata.Reference)
+}
+
+// validateObjectMeta checks the parts of the metadata of a document that the API server rejects even though the schema allows them.
+func validateObjectMeta(incoming map[interface{}]interface{}) []error {
+	problems := objectmeta.Check(incoming)
+	errors := make([]error, 0, len(problems))
+	for _, p := range problems {
+		if p.Key {
+			errors = append(errors, NewYamlPathError(p.Path, "", NewInvalidMetadataKeyError(p.Field, p.Value, p.Messages...)))
+			continue
+		}
+		errors = append(errors, NewYamlPathError(p.Path, p.Value, NewInvalidMetadataError(p.Field, p.Value, p.Messages...)))
+	}
+	return errors
+}
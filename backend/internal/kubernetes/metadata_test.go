@@ -0,0 +1,109 @@
+package kubernetes_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+)
+
+func TestObjectMeta(t *testing.T) {
+	const objectMeta = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+	stringMap := &kubernetes.AdditionalProperties{Property: kubernetes.Property{Type: "string"}}
+	v := kubernetes.NewValidator(&resolver{
+		lookup: &kubernetes.Swagger{
+			Definitions: map[string]*kubernetes.Schema{
+				objectMeta: &kubernetes.Schema{
+					Properties: map[string]*kubernetes.Property{
+						"name":         &kubernetes.Property{Type: "string"},
+						"generateName": &kubernetes.Property{Type: "string"},
+						"namespace":    &kubernetes.Property{Type: "string"},
+						"labels":       &kubernetes.Property{Type: "object", AdditionalProperties: stringMap},
+						"annotations":  &kubernetes.Property{Type: "object", AdditionalProperties: stringMap},
+					},
+				},
+			},
+		},
+		version: "v1.12",
+	})
+	schema := &kubernetes.Schema{
+		Properties: map[string]*kubernetes.Property{
+			"metadata": &kubernetes.Property{Reference: objectMeta},
+		},
+	}
+
+	testcases := []struct {
+		name     string
+		metadata map[interface{}]interface{}
+		paths    []string
+	}{
+		{
+			name: "valid metadata",
+			metadata: map[interface{}]interface{}{
+				"name":         "web.example-1",
+				"generateName": "web-",
+				"namespace":    "prod",
+				"labels": map[interface{}]interface{}{
+					"app.kubernetes.io/name": "web",
+					"tier":                   "",
+				},
+				"annotations": map[interface{}]interface{}{
+					"Example.com/Owner": "team a, with spaces",
+				},
+			},
+			paths: []string{},
+		},
+		{
+			name: "invalid names",
+			metadata: map[interface{}]interface{}{
+				"name":         "My_App",
+				"generateName": "Web-",
+				"namespace":    "my.namespace",
+			},
+			paths: []string{"metadata.generateName", "metadata.name", "metadata.namespace"},
+		},
+		{
+			name: "invalid labels and annotations",
+			metadata: map[interface{}]interface{}{
+				"labels": map[interface{}]interface{}{
+					strings.Repeat("a", 64):    "ok",
+					"example.com/":             "ok",
+					"tier":                     "front end",
+					"version":                  strings.Repeat("1", 64),
+					"kubernetes.io/managed-by": "kubeyaml",
+				},
+				"annotations": map[interface{}]interface{}{
+					"a/b/c": "too many slashes",
+					"Large": strings.Repeat("x", 256*1024),
+				},
+			},
+			paths: []string{
+				"metadata.annotations",
+				"metadata.annotations.a/b/c",
+				"metadata.labels." + strings.Repeat("a", 64),
+				"metadata.labels.example.com/",
+				"metadata.labels.tier",
+				"metadata.labels.version",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := v.Validate(map[interface{}]interface{}{"metadata": tc.metadata}, schema)
+			paths := make([]string, 0)
+			for _, err := range errs {
+				pathErr := err.(*kubernetes.YamlPathError)
+				if _, ok := pathErr.Err.(*kubernetes.InvalidMetadataError); !ok {
+					t.Fatalf("expected only metadata errors but got %v", err)
+				}
+				paths = append(paths, pathErr.Path)
+			}
+			sort.Strings(paths)
+			if !reflect.DeepEqual(paths, tc.paths) {
+				t.Fatalf("expected errors at %v but got %v", tc.paths, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package kubernetes
+
+import "github.com/chuckha/kubeyaml.com/backend/internal/shared/number"
+
+// validateInteger checks the type of an integer and that it fits its format.
+func validateInteger(key, format string, value interface{}) error {
+	return numberError(key, "integer", value, number.CheckInteger(format, value))
+}
+
+// validateNumber checks the type of a number and that it fits its format.
+func validateNumber(key, format string, value interface{}) error {
+	return numberError(key, "number", value, number.CheckNumber(format, value))
+}
+
+// numberError turns what the number package found wrong with value into an error of this package.
+func numberError(key, expected string, value interface{}, err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *number.RangeError:
+		return NewOutOfRangeError(key, e.Format, value)
+	}
+	return NewWrongTypeError(key, expected, value)
+}
@@ -0,0 +1,14 @@
+package kubernetes
+
+import "github.com/chuckha/kubeyaml.com/backend/internal/shared/quantity"
+
+// validateQuantity checks a quantity written as either a string or a number.
+func validateQuantity(key string, value interface{}) error {
+	switch quantity.Check(value) {
+	case nil:
+		return nil
+	case quantity.ErrWrongType:
+		return NewWrongTypeError(key, "quantity", value)
+	}
+	return NewInvalidQuantityError(key, value.(string))
+}
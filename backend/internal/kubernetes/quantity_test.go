@@ -0,0 +1,44 @@
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
+)
+
+func TestQuantity(t *testing.T) {
+	const quantity = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+	v := kubernetes.NewValidator(&resolver{
+		lookup: &kubernetes.Swagger{
+			Definitions: map[string]*kubernetes.Schema{
+				quantity: &kubernetes.Schema{Type: "string"},
+			},
+		},
+		version: "v1.12",
+	})
+	schema := &kubernetes.Schema{
+		Properties: map[string]*kubernetes.Property{
+			"memory": &kubernetes.Property{Reference: quantity},
+		},
+	}
+
+	valid := []interface{}{"512Mi", "1Gi", "500m", "0.5", ".5", "1.", "+1", "-1k", "100n", "1u", "1e3", "1E-3", "2E", "1", 2, 0.25, int64(5)}
+	for _, value := range valid {
+		if errs := v.Validate(map[interface{}]interface{}{"memory": value}, schema); len(errs) != 0 {
+			t.Errorf("expected %v to be a valid quantity but got %v", value, errs)
+		}
+	}
+
+	invalid := []interface{}{"512mb", "0.5 cores", "1Gb", "Mi", "", "1.2.3", "1e", "1ki", "1U", "1nm", true}
+	for _, value := range invalid {
+		errs := v.Validate(map[interface{}]interface{}{"memory": value}, schema)
+		if len(errs) != 1 {
+			t.Errorf("expected %v to be an invalid quantity but got %v", value, errs)
+		}
+	}
+
+	errs := v.Validate(map[interface{}]interface{}{"memory": "512mb"}, schema)
+	if _, ok := errs[0].(*kubernetes.YamlPathError).Err.(*kubernetes.InvalidQuantityError); !ok {
+		t.Errorf("expected an InvalidQuantityError but got %v", errs[0])
+	}
+}
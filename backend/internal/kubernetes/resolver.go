@@ -4,36 +4,71 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/data"
 )
 
 // Resolver looks up a schema based on the schema key.
+// The swagger file is only parsed the first time a schema is resolved.
 type Resolver struct {
 	version string
+	custom  *CustomResources
+
+	once    sync.Once
 	swagger *Swagger
+	err     error
 }
 
-// NewResolver loads a swagger file, keeps track of the version and returns an instantiated resolver.
+// NewResolver keeps track of the version and returns an instantiated resolver.
+// It returns an error if there is no swagger file for the version.
 func NewResolver(version string) (*Resolver, error) {
-	staticFiles := &data.StaticFiles{}
-	swagger := &Swagger{}
-	if err := json.Unmarshal(staticFiles.Swagger(version), swagger); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal swagger file: %v", err)
+	if !data.Has(version) {
+		return nil, fmt.Errorf("no swagger file for kubernetes version %q", version)
 	}
-
 	return &Resolver{
 		version: version,
-		swagger: swagger,
 	}, nil
 }
 
+// load parses the swagger file once.
+func (r *Resolver) load() (*Swagger, error) {
+	r.once.Do(func() {
+		f, err := data.Open(r.version)
+		if err != nil {
+			r.err = err
+			return
+		}
+		defer f.Close()
+		swagger := &Swagger{}
+		if err := json.NewDecoder(f).Decode(swagger); err != nil {
+			r.err = fmt.Errorf("failed to unmarshal swagger file: %v", err)
+			return
+		}
+		r.swagger = swagger
+	})
+	return r.swagger, r.err
+}
+
+// AddCustomResources makes the resolver find the schemas of custom resources.
+func (r *Resolver) AddCustomResources(custom *CustomResources) {
+	r.custom = custom
+}
+
 func (r *Resolver) Resolve(schemaKey string) (*Schema, error) {
+	swagger, err := r.load()
+	if err != nil {
+		return nil, err
+	}
 	schemaKey = strings.TrimPrefix(schemaKey, "#/definitions/")
-	def, ok := r.swagger.Definitions[schemaKey]
+	if r.custom != nil {
+		if def, ok := r.custom.Resolve(schemaKey); ok {
+			return def, nil
+		}
+	}
+	def, ok := swagger.Definitions[schemaKey]
 	if !ok {
-		// Ideally we return the passed in apiVersion but that requires a reshuffling of some functions
-		return nil, NewYamlPathError([]string{"apiVersion"}, "", NewUnknownSchemaError(schemaKey))
+		return nil, NewUnknownSchemaError(schemaKey)
 	}
 	return def, nil
 }
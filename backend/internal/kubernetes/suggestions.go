@@ -0,0 +1,56 @@
+package kubernetes
+
+import (
+	"sort"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/suggest"
+)
+
+// suggestions lists the properties of schema that look like key, then the places deeper in the schema where key exists,
+// such as `template.spec.containers` for `containers` written directly under the spec of a Deployment.
+func (v *Validator) suggestions(key string, schema *Schema) []string {
+	return suggest.Keys(key, schemaNode{schema: schema, validator: v})
+}
+
+// schemaNode lets the suggest package walk a schema. It's a value so the same schema is the same node.
+type schemaNode struct {
+	schema    *Schema
+	validator *Validator
+}
+
+func (n schemaNode) Keys() []string {
+	return propertyNames(n.schema)
+}
+
+func (n schemaNode) Child(key string) suggest.Node {
+	child := n.validator.childSchema(n.schema.Properties[key])
+	if child == nil {
+		return nil
+	}
+	return schemaNode{schema: child, validator: n.validator}
+}
+
+// childSchema is the schema of an object or of the items of an array of objects.
+func (v *Validator) childSchema(property *Property) *Schema {
+	ref := property.Reference
+	if property.Type == "array" && property.Items != nil {
+		ref = property.Items.Reference
+	}
+	if ref == "" {
+		return nil
+	}
+	schema, err := v.resolver.Resolve(ref)
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
+func propertyNames(schema *Schema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
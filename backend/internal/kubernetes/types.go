@@ -27,6 +27,8 @@ type Schema struct {
 	Type string
 	// Format is the format of the type when Type is "string"
 	Format string
+	// PreserveUnknownFields is set by custom resource definitions on objects that keep keys their properties don't list.
+	PreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields"`
 }
 
 // Property is a single property, or field, of a schema.
@@ -40,10 +42,13 @@ type Property struct {
 	// Items is required for array types. This tells us what types are inside the array.
 	Items *Items
 	// AdditionalProperties is an optional field for object types defining what kind of key/value pairs will be found on the object.
-	// TODO(chuckha) make this a pointer type
-	AdditionalProperties AdditionalProperties
+	AdditionalProperties *AdditionalProperties
 	// Reference is a reference to another schema in our list of definitions.
 	Reference string `json:"$ref"`
+	// Enum lists every allowed value, it's set by custom resource definitions.
+	Enum []interface{}
+	// Nullable means the value may be null, it's set by custom resource definitions.
+	Nullable bool
 }
 
 // String implements the Stringer interface and gives us a nice human readable output.
@@ -80,6 +85,28 @@ type Items struct {
 	Reference string `json:"$ref"`
 	// Items can be an array of arrays of arrays of ...
 	Items *Items
+	// Format is a sub type of Type.
+	Format string
+	// Enum lists every allowed value.
+	Enum []interface{}
+	// Nullable means the item may be null.
+	Nullable bool
+	// AdditionalProperties defines the values when the items are maps.
+	AdditionalProperties *AdditionalProperties
+}
+
+// property describes a single item so it can be validated like any other value.
+func (i *Items) property() *Property {
+	return &Property{
+		Description:          i.Description,
+		Type:                 i.Type,
+		Format:               i.Format,
+		Items:                i.Items,
+		AdditionalProperties: i.AdditionalProperties,
+		Reference:            i.Reference,
+		Enum:                 i.Enum,
+		Nullable:             i.Nullable,
+	}
 }
 
 // Kind is the kind we all know and love from ObjectMeta.
@@ -108,9 +135,8 @@ type GroupVersionKind struct {
 	Version string
 }
 
-// AdditionalProperties define the type found in objects.
-// Kubernetes only uses string:string dicts, but this might break on more advanced use cases.
+// AdditionalProperties define the type of every value found in objects such as labels or resource limits.
+// It is a whole property so values can be references or arrays.
 type AdditionalProperties struct {
-	// Type will almost always be a string
-	Type string
+	Property
 }
@@ -2,7 +2,9 @@ package kubernetes
 
 import (
 	"fmt"
-	"time"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/number"
+	"github.com/chuckha/kubeyaml.com/backend/internal/shared/quantity"
 )
 
 type resolver interface {
@@ -35,8 +37,13 @@ func (v *Validator) Version() string {
 // Validate sees incoming data and validates it against the known schemas.
 // This is recursive so it does a depth first search of all key/values.
 // TODO(chuckha) turn this into a stack-based dfs search.
+// Names and labels in ObjectMeta are checked after the schema.
 func (v *Validator) Validate(incoming map[interface{}]interface{}, schema *Schema) []error {
-	return v.validate(incoming, schema, []string{})
+	errors := v.validate(incoming, schema, []string{})
+	if hasObjectMeta(schema) {
+		errors = append(errors, validateObjectMeta(incoming)...)
+	}
+	return errors
 }
 
 // validate is the meat and potatoes of this entire application.
@@ -51,6 +58,11 @@ func (v *Validator) Validate(incoming map[interface{}]interface{}, schema *Schem
 func (v *Validator) validate(incoming map[interface{}]interface{}, schema *Schema, path []string) []error {
 	errors := make([]error, 0)
 
+	// an object without any properties can hold anything
+	if schema.Type == "object" && len(schema.Properties) == 0 {
+		return errors
+	}
+
 	// Validate each key one at a time descending as deep and as wide as the key goes.
 	for k, value := range incoming {
 		// Keep track of where we are
@@ -68,128 +80,173 @@ func (v *Validator) validate(incoming map[interface{}]interface{}, schema *Schem
 
 		property, ok := schema.Properties[key]
 		if !ok {
-			errors = append(errors, NewYamlPathError(tlp, "", NewUnknownKeyError(key)))
+			if !schema.PreserveUnknownFields {
+				errors = append(errors, NewYamlPathError(tlp, "", NewUnknownKeyError(key, v.suggestions(key, schema)...)))
+			}
 			continue
 		}
 
-		switch property.Type {
-		case "string":
-			// TODO: formats?
-			if _, ok := value.(string); !ok {
-				errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "string", value)))
-			}
-		case "integer":
-			// ignore property.Format until it causes a bug
-			if _, ok := value.(int); !ok {
-				errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "integer", value)))
-			}
-		case "boolean":
-			if _, ok := value.(bool); !ok {
-				errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "boolean", value)))
-			}
-		case "object":
-			// this is for things like labels; map[interface{}]interface{} looks weird but that's how our yaml parser works.
-			if _, ok := value.(map[interface{}]interface{}); !ok {
-				errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "map[interface{}]interface{}", value)))
-			}
-		case "array":
-			items, ok := value.([]interface{})
-			if !ok {
-				errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "[]interface{}", value)))
-				continue
-			}
+		errors = append(errors, v.validateProperty(key, value, property, tlp)...)
+	}
 
-			switch property.Items.Type {
-			case "string":
-				for _, item := range items {
-					if _, ok := item.(string); !ok {
-						errors = append(errors, NewWrongTypeError(key, "string", item))
-					}
-				}
-			// assume it's an array of objects
-			default:
-				// TODO: check that items is not nil
-				schema, err := v.resolver.Resolve(property.Items.Reference)
-				if err != nil {
-					fmt.Println(key, property)
-					errors = append(errors, NewYamlPathError(tlp, schema, err))
-					continue
-				}
+	return errors
+}
+
+// validateProperty checks a single value against the property that describes it.
+func (v *Validator) validateProperty(key string, value interface{}, property *Property, tlp []string) []error {
+	errors := make([]error, 0)
+
+	if value == nil && property.Nullable {
+		return errors
+	}
+	if len(property.Enum) > 0 && !inEnum(value, property.Enum) {
+		errors = append(errors, NewYamlPathError(tlp, value, NewNotInEnumError(key, value, property.Enum)))
+	}
+
+	switch property.Type {
+	case "string":
+		// TODO: formats?
+		if _, ok := value.(string); !ok {
+			errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "string", value)))
+		}
+	case "integer":
+		if err := validateInteger(key, property.Format, value); err != nil {
+			errors = append(errors, NewYamlPathError(tlp, value, err))
+		}
+	case "number":
+		if err := validateNumber(key, property.Format, value); err != nil {
+			errors = append(errors, NewYamlPathError(tlp, value, err))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "boolean", value)))
+		}
+	case "object":
+		// this is for things like labels; map[interface{}]interface{} looks weird but that's how our yaml parser works.
+		object, ok := value.(map[interface{}]interface{})
+		if !ok {
+			errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "object", value)))
+			return errors
+		}
+		errors = append(errors, v.validateMapValues(object, property.AdditionalProperties, tlp)...)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "array", value)))
+			return errors
+		}
+
+		if property.Items == nil {
+			// without a schema for the items anything goes
+			return errors
+		}
+		item := property.Items.property()
+		for i, value := range items {
+			// Sequences keys will have the index following the key
+			// e.g. spec.template.containers.1 (the problem is in the second one)
+			tlpIdx := append(tlp[:len(tlp):len(tlp)], fmt.Sprintf("%d", i))
+			errors = append(errors, v.validateProperty(key, value, item, tlpIdx)...)
+		}
+	// default is some k8s object
+	default:
+		errors = append(errors, v.validateReference(key, value, property, tlp)...)
+	}
+	return errors
+}
+
+// validateReference checks a value against the schema that the property references.
+func (v *Validator) validateReference(key string, value interface{}, property *Property, tlp []string) []error {
+	errors := make([]error, 0)
+	schema, err := v.resolver.Resolve(property.Reference)
+	if err != nil {
+		// DEBUG LINE good to use if there is a weird error
+		// fmt.Println(key, property)
+		return append(errors, NewYamlPathError(tlp, property.Reference, err))
+	}
+
+	// Types with custom marshalling, such as JSONSchemaPropsOrBool, have neither a type nor properties
+	if schema.Type == "" && len(schema.Properties) == 0 {
+		return errors
+	}
 
-				for i, item := range items {
-					// Sequences keys will have the index following the key
-					// e.g. spec.template.containers.1 (the problem is in the second one)
-					tlpIdx := append(tlp, fmt.Sprintf("%d", i))
-					if len(schema.Required) > 0 {
-						if errs := resolveRequiredFields(key, item, schema, tlpIdx); len(errs) > 0 {
-							errors = append(errors, errs...)
-						}
-					}
-
-					if errs := v.handleObject(key, item, tlpIdx, schema); len(errs) > 0 {
-						errors = append(errors, errs...)
-					}
+	if len(schema.Required) > 0 {
+		if errs := resolveRequiredFields(key, value, schema, tlp); len(errs) > 0 {
+			errors = append(errors, errs...)
+		}
+	}
+
+	// Bail if the object reference is a type rename
+	if schema.Type == "string" {
+		// format must be set if type is string, except for quantities
+		switch schema.Format {
+		case "int-or-string":
+			if _, ok := value.(string); !ok {
+				if !number.IsInteger(value) {
+					errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "int-or-string", value)))
 				}
 			}
-		// default is some k8s object
-		default:
-			schema, err := v.resolver.Resolve(property.Reference)
-			if err != nil {
-				// DEBUG LINE good to use if there is a weird error
-				// fmt.Println(key, property)
-				errors = append(errors, NewYamlPathError(tlp, property.Reference, err))
-				continue
+		case "date-time":
+			// nil is valid object reference
+			if value == nil {
+				return errors
 			}
-
-			if len(schema.Required) > 0 {
-				if errs := resolveRequiredFields(key, value, schema, tlp); len(errs) > 0 {
-					errors = append(errors, errs...)
-				}
+			if err := validateDateTime(key, property.Reference, value); err != nil {
+				errors = append(errors, NewYamlPathError(tlp, value, err))
 			}
-
-			// Bail if the object reference is a type rename
-			if schema.Type == "string" {
-				// format must be set if type is string
-				switch schema.Format {
-				case "int-or-string":
-					if _, ok := value.(string); !ok {
-						if _, ok2 := value.(int); !ok2 {
-							errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "int-or-string", value)))
-						}
-					}
-				case "date-time":
-					// nil is valid object reference
-					if value == nil {
-						continue
-					}
-					date, ok := value.(string)
-					if !ok {
-						errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "string", value)))
-						continue
-					}
-					if _, err := time.Parse("2006-01-02T15:04:05Z", date); err != nil {
-						errors = append(errors, NewYamlPathError(tlp, value, NewWrongTypeError(key, "time.Time", value)))
-					}
-				default:
-					errors = append(errors, NewYamlPathError(tlp, value, NewUnknownFormatError(schema.Format)))
-				}
-				continue
+		case "":
+			if !quantity.IsRef(property.Reference) {
+				errors = append(errors, NewYamlPathError(tlp, value, NewUnknownFormatError(schema.Format)))
+				return errors
 			}
-			if errs := v.handleObject(key, value, tlp, schema); len(errs) > 0 {
-				errors = append(errors, errs...)
+			if err := validateQuantity(key, value); err != nil {
+				errors = append(errors, NewYamlPathError(tlp, value, err))
 			}
+		default:
+			errors = append(errors, NewYamlPathError(tlp, value, NewUnknownFormatError(schema.Format)))
 		}
+		return errors
 	}
+	if errs := v.handleObject(key, value, tlp, schema); len(errs) > 0 {
+		errors = append(errors, errs...)
+	}
+	return errors
+}
 
+// validateMapValues checks every value of a map against the additionalProperties of its property.
+// The map key is added to the path of any error.
+func (v *Validator) validateMapValues(object map[interface{}]interface{}, additional *AdditionalProperties, path []string) []error {
+	errors := make([]error, 0)
+	if additional == nil || (additional.Type == "" && additional.Reference == "") {
+		return errors
+	}
+	for k, value := range object {
+		key, ok := k.(string)
+		if !ok {
+			errors = append(errors, NewYamlPathError(path, "", NewKeyNotStringError(k)))
+			continue
+		}
+		tlp := append(path[:len(path):len(path)], key)
+		errors = append(errors, v.validateProperty(key, value, &additional.Property, tlp)...)
+	}
 	return errors
 }
 
+// inEnum compares scalars by their string form since JSON and YAML decode numbers differently.
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
 // handleObject takes a key that has a value that will be of type map[interface{}]interface{}
 // handleObject takes the current path to the key that is being validated and the schema of the object hidden under the value interface.
 func (v *Validator) handleObject(key string, value interface{}, path []string, schema *Schema) []error {
 	object, ok := value.(map[interface{}]interface{})
 	if !ok {
-		return []error{NewYamlPathError(path, value, NewWrongTypeError(key, "map[interface{}]interface{}", value))}
+		return []error{NewYamlPathError(path, value, NewWrongTypeError(key, "object", value))}
 	}
 	return v.validate(object, schema, path)
 }
@@ -198,7 +255,7 @@ func (v *Validator) handleObject(key string, value interface{}, path []string, s
 func resolveRequiredFields(key string, objectValue interface{}, schema *Schema, path []string) []error {
 	mapvalues, ok := objectValue.(map[interface{}]interface{})
 	if !ok {
-		return []error{NewWrongTypeError(key, "map[interface{}]interface{}", objectValue)}
+		return []error{NewYamlPathError(path, objectValue, NewWrongTypeError(key, "object", objectValue))}
 	}
 	requiredKeys := map[string]bool{}
 	for _, k := range schema.Required {
@@ -207,7 +264,7 @@ func resolveRequiredFields(key string, objectValue interface{}, schema *Schema,
 	for k := range mapvalues {
 		realKey, ok := k.(string)
 		if !ok {
-			return []error{NewKeyNotStringError(key)}
+			return []error{NewYamlPathError(path, "", NewKeyNotStringError(k))}
 		}
 		if _, ok := requiredKeys[realKey]; ok {
 			requiredKeys[realKey] = true
@@ -215,7 +272,6 @@ func resolveRequiredFields(key string, objectValue interface{}, schema *Schema,
 	}
 	for k, found := range requiredKeys {
 		if !found {
-			path = append(path, key)
 			return []error{NewRequiredKeyNotFoundError(k, path)}
 		}
 	}
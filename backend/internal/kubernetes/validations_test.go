@@ -1,6 +1,7 @@
 package kubernetes_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes"
@@ -121,6 +122,179 @@ func TestValidate(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:    "numbers",
+			version: "v1.12",
+			incoming: map[interface{}]interface{}{
+				interface{}("replicas"):    interface{}(99999999999),
+				interface{}("generation"):  interface{}(int64(9000000000)),
+				interface{}("tooBig"):      interface{}(uint64(18446744073709551615)),
+				interface{}("utilization"): interface{}(0.75),
+				interface{}("weight"):      interface{}(3),
+				interface{}("ratio"):       interface{}("half"),
+			},
+			schema: &kubernetes.Schema{
+				Properties: map[string]*kubernetes.Property{
+					"replicas":    &kubernetes.Property{Type: "integer", Format: "int32"},
+					"generation":  &kubernetes.Property{Type: "integer", Format: "int64"},
+					"tooBig":      &kubernetes.Property{Type: "integer", Format: "int64"},
+					"utilization": &kubernetes.Property{Type: "number", Format: "double"},
+					"weight":      &kubernetes.Property{Type: "number"},
+					"ratio":       &kubernetes.Property{Type: "number"},
+				},
+			},
+			check: func(errors []error, t *testing.T) {
+				if len(errors) != 3 {
+					t.Fatalf("expected replicas, tooBig and ratio to fail but got %v", errors)
+				}
+			},
+		},
+		{
+			name: "typed maps",
+			definitions: map[string]*kubernetes.Schema{
+				"io.k8s.apimachinery.pkg.api.resource.Quantity": &kubernetes.Schema{Type: "string"},
+			},
+			version: "v1.12",
+			incoming: map[interface{}]interface{}{
+				interface{}("labels"): interface{}(map[interface{}]interface{}{
+					interface{}("app"):  interface{}(3),
+					interface{}("tier"): interface{}("web"),
+				}),
+				interface{}("limits"): interface{}(map[interface{}]interface{}{
+					interface{}("cpu"):    interface{}("500m"),
+					interface{}("memory"): interface{}(1024),
+					interface{}("gpu"):    interface{}([]interface{}{1}),
+				}),
+				interface{}("groups"): interface{}(map[interface{}]interface{}{
+					interface{}("admins"): interface{}([]interface{}{"alice", 2}),
+				}),
+			},
+			schema: &kubernetes.Schema{
+				Properties: map[string]*kubernetes.Property{
+					"labels": &kubernetes.Property{
+						Type:                 "object",
+						AdditionalProperties: &kubernetes.AdditionalProperties{Property: kubernetes.Property{Type: "string"}},
+					},
+					"limits": &kubernetes.Property{
+						Type:                 "object",
+						AdditionalProperties: &kubernetes.AdditionalProperties{Property: kubernetes.Property{Reference: "io.k8s.apimachinery.pkg.api.resource.Quantity"}},
+					},
+					"groups": &kubernetes.Property{
+						Type: "object",
+						AdditionalProperties: &kubernetes.AdditionalProperties{Property: kubernetes.Property{
+							Type:  "array",
+							Items: &kubernetes.Items{Type: "string"},
+						}},
+					},
+				},
+			},
+			check: func(errors []error, t *testing.T) {
+				paths := map[string]bool{}
+				for _, err := range errors {
+					paths[err.(*kubernetes.YamlPathError).Path] = true
+				}
+				want := map[string]bool{"labels.app": true, "limits.gpu": true, "groups.admins.1": true}
+				if !reflect.DeepEqual(paths, want) {
+					t.Fatalf("expected errors at %v but got %v", want, errors)
+				}
+			},
+		},
+		{
+			name: "arrays",
+			definitions: map[string]*kubernetes.Schema{
+				"io.k8s.apimachinery.pkg.util.intstr.IntOrString": &kubernetes.Schema{Type: "string", Format: "int-or-string"},
+			},
+			version: "v1.12",
+			incoming: map[interface{}]interface{}{
+				interface{}("supplementalGroups"): interface{}([]interface{}{1000, "nobody"}),
+				interface{}("matrix"): interface{}([]interface{}{
+					interface{}([]interface{}{1, 2}),
+					interface{}([]interface{}{3, true}),
+					interface{}(4),
+				}),
+				interface{}("ports"):    interface{}([]interface{}{80, "http", 1.5}),
+				interface{}("anything"): interface{}([]interface{}{1, "two", nil}),
+			},
+			schema: &kubernetes.Schema{
+				Properties: map[string]*kubernetes.Property{
+					"supplementalGroups": &kubernetes.Property{
+						Type:  "array",
+						Items: &kubernetes.Items{Type: "integer", Format: "int64"},
+					},
+					"matrix": &kubernetes.Property{
+						Type:  "array",
+						Items: &kubernetes.Items{Type: "array", Items: &kubernetes.Items{Type: "integer"}},
+					},
+					"ports": &kubernetes.Property{
+						Type:  "array",
+						Items: &kubernetes.Items{Reference: "io.k8s.apimachinery.pkg.util.intstr.IntOrString"},
+					},
+					"anything": &kubernetes.Property{
+						Type: "array",
+					},
+				},
+			},
+			check: func(errors []error, t *testing.T) {
+				paths := map[string]bool{}
+				for _, err := range errors {
+					paths[err.(*kubernetes.YamlPathError).Path] = true
+				}
+				want := map[string]bool{"supplementalGroups.1": true, "matrix.1.1": true, "matrix.2": true, "ports.2": true}
+				if !reflect.DeepEqual(paths, want) {
+					t.Fatalf("expected errors at %v but got %v", want, errors)
+				}
+			},
+		},
+		{
+			name: "unknown keys suggest what was meant",
+			definitions: map[string]*kubernetes.Schema{
+				"PodTemplateSpec": &kubernetes.Schema{
+					Properties: map[string]*kubernetes.Property{
+						"spec": &kubernetes.Property{Reference: "PodSpec"},
+					},
+				},
+				"PodSpec": &kubernetes.Schema{
+					Properties: map[string]*kubernetes.Property{
+						"containers": &kubernetes.Property{Type: "array", Items: &kubernetes.Items{Reference: "Container"}},
+					},
+				},
+				"Container": &kubernetes.Schema{
+					Properties: map[string]*kubernetes.Property{
+						"image": &kubernetes.Property{Type: "string"},
+					},
+				},
+			},
+			version: "v1.12",
+			incoming: map[interface{}]interface{}{
+				interface{}("replica"):    interface{}(1),
+				interface{}("containers"): interface{}([]interface{}{}),
+				interface{}("Paused"):     interface{}(true),
+				interface{}("banana"):     interface{}(true),
+			},
+			schema: &kubernetes.Schema{
+				Properties: map[string]*kubernetes.Property{
+					"replicas": &kubernetes.Property{Type: "integer"},
+					"paused":   &kubernetes.Property{Type: "boolean"},
+					"template": &kubernetes.Property{Reference: "PodTemplateSpec"},
+				},
+			},
+			check: func(errors []error, t *testing.T) {
+				suggestions := map[string][]string{}
+				for _, err := range errors {
+					pathErr := err.(*kubernetes.YamlPathError)
+					suggestions[pathErr.Path] = pathErr.Err.(*kubernetes.UnknownKeyError).Suggestions
+				}
+				want := map[string][]string{
+					"replica":    []string{"replicas"},
+					"containers": []string{"template.spec.containers"},
+					"Paused":     []string{"paused"},
+					"banana":     []string{},
+				}
+				if !reflect.DeepEqual(suggestions, want) {
+					t.Fatalf("expected suggestions %v but got %v", want, suggestions)
+				}
+			},
+		},
 	}
 
 	for _, tc := range testcases {
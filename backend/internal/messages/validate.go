@@ -0,0 +1,9 @@
+package messages
+
+// DocumentResult is the validation result of a single document in a response to /validate.
+type DocumentResult struct {
+	// Index is the position of the document in the submitted YAML stream.
+	Index int
+	// Errors maps a kubernetes version to the errors found validating against that version.
+	Errors map[string][]error
+}
@@ -0,0 +1,19 @@
+package validation
+
+import "github.com/chuckha/kubeyaml.com/backend/internal/shared/advice"
+
+// advise finds things the API server accepts but that are likely to cause trouble, such as containers without limits.
+// Everything it returns is a warning or info.
+func advise(kind string, incoming map[interface{}]interface{}) []error {
+	found := advice.Find(kind, incoming)
+	errors := make([]error, 0, len(found))
+	for _, a := range found {
+		switch a.Kind {
+		case advice.LatestImageTag:
+			errors = append(errors, NewYamlPathError(a.Path, a.Image, NewLatestImageTagError(a.Image)))
+		case advice.MissingResources:
+			errors = append(errors, NewYamlPathError(a.Path, "", NewMissingResourcesError(a.Container, a.Field)))
+		}
+	}
+	return errors
+}
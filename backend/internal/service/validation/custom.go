@@ -0,0 +1,31 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+)
+
+// CustomResources are the schemas of custom resource definitions.
+// They are looked up alongside the schemas in the swagger files.
+type CustomResources struct {
+	registry    *crd.Registry
+	definitions map[string]*Schema
+}
+
+// NewCustomResources unmarshals every schema in the registry.
+func NewCustomResources(registry *crd.Registry) (*CustomResources, error) {
+	definitions := make(map[string]*Schema)
+	for key, raw := range registry.Definitions() {
+		schema := &Schema{}
+		if err := json.Unmarshal(raw, schema); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal custom resource schema %s: %v", key, err)
+		}
+		definitions[key] = schema
+	}
+	return &CustomResources{
+		registry:    registry,
+		definitions: definitions,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kubeyaml.com/backend/internal/kubernetes/crd"
+)
+
+const backups = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: backups.stable.example.com
+spec:
+  group: stable.example.com
+  names:
+    kind: Backup
+    plural: backups
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            x-kubernetes-preserve-unknown-fields: true
+            properties:
+              note:
+                type: string
+                nullable: true
+              schedule:
+                type: string
+                enum: [hourly, daily]
+              retries:
+                type: integer
+`
+
+func TestCustomResources(t *testing.T) {
+	registry := crd.NewRegistry()
+	if err := registry.Load(strings.NewReader(backups)); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := NewCustomResources(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := loadSwagger(t).WithCustomResources(custom)
+	schema, err := s.FromVersionKind("stable.example.com/v1", "Backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name string
+		spec map[interface{}]interface{}
+		kind string
+	}{
+		{
+			name: "null is allowed when nullable",
+			spec: map[interface{}]interface{}{"note": nil},
+		},
+		{
+			name: "unknown keys are kept",
+			spec: map[interface{}]interface{}{"schedule": "daily", "target": "s3://backups"},
+		},
+		{
+			name: "value not in the enum",
+			spec: map[interface{}]interface{}{"schedule": "weekly"},
+			kind: KindNotInEnum,
+		},
+		{
+			name: "known keys are still checked",
+			spec: map[interface{}]interface{}{"retries": "three", "target": "s3://backups"},
+			kind: KindWrongType,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := s.Validate(map[interface{}]interface{}{"spec": tc.spec}, schema, []string{})
+			if tc.kind == "" {
+				if len(errs) != 0 {
+					t.Fatalf("expected no errors but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("expected 1 error but got %v", errs)
+			}
+			if kind := detailOf(errs[0]).Kind; kind != tc.kind {
+				t.Fatalf("expected a %s error but got %s: %v", tc.kind, kind, errs[0])
+			}
+		})
+	}
+}